@@ -0,0 +1,323 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+)
+
+// A http.FileSystem serving the contents of a zip or a tar archive. The files
+// are read straight from the archive, so that serving the ranges of large
+// files does not require extracting them.
+type ArchiveFileSystem struct {
+	MemoryFileSystem
+	closer func() error
+}
+
+// Makes a compressed stream seekable. Seeking forward skips the data, seeking
+// backward restarts the decompression.
+type reopenReader struct {
+	open   func() (io.ReadCloser, error)
+	size   int64
+	rc     io.ReadCloser
+	pos    int64
+	target int64
+}
+
+func (r *reopenReader) Read(p []byte) (int, error) {
+	if r.target >= r.size {
+		return 0, io.EOF
+	}
+
+	if r.rc == nil || r.target < r.pos {
+		if r.rc != nil {
+			r.rc.Close()
+		}
+		rc, err := r.open()
+		if err != nil {
+			return 0, err
+		}
+		r.rc = rc
+		r.pos = 0
+	}
+
+	if r.target > r.pos {
+		skipped, err := io.CopyN(io.Discard, r.rc, r.target-r.pos)
+		r.pos += skipped
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.rc.Read(p)
+	r.pos += int64(n)
+	r.target = r.pos
+	return n, err
+}
+
+func (r *reopenReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.target
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, fmt.Errorf("invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position")
+	}
+	r.target = offset
+	return offset, nil
+}
+
+func (r *reopenReader) Close() error {
+	if r.rc == nil {
+		return nil
+	}
+	err := r.rc.Close()
+	r.rc = nil
+	return err
+}
+
+// Release the underlying archive file if the filesystem has opened it.
+func (fs *ArchiveFileSystem) Close() error {
+	if fs.closer == nil {
+		return nil
+	}
+	return fs.closer()
+}
+
+func (fs *ArchiveFileSystem) addDir(name string, modTime time.Time) error {
+	node, err := fs.root.mkdirAll(splitPath(path.Clean("/" + name)))
+	if err != nil {
+		return err
+	}
+	node.modTime = modTime
+	return nil
+}
+
+func (fs *ArchiveFileSystem) addFile(name string, size int64, modTime time.Time,
+	open func() io.ReadSeeker) error {
+
+	components := splitPath(path.Clean("/" + name))
+	if len(components) == 0 {
+		return nil
+	}
+
+	return fs.root.addFile(components, &memoryNode{
+		name:    components[len(components)-1],
+		size:    size,
+		modTime: modTime,
+		mode:    0444,
+		open:    open,
+	})
+}
+
+func newArchiveFileSystem() *ArchiveFileSystem {
+	return &ArchiveFileSystem{MemoryFileSystem: MemoryFileSystem{newMemoryDir("/")}}
+}
+
+// Build a filesystem serving the contents of a zip archive. The stored members
+// are read directly from the archive, the compressed ones are decompressed on
+// the fly. The directories missing in the archive are synthesized; like in
+// NewMemoryFileSystem, a directory is as recent as its newest member.
+func NewZipFileSystem(r io.ReaderAt, size int64) (*ArchiveFileSystem, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := newArchiveFileSystem()
+	for _, file := range zr.File {
+		file := file
+		info := file.FileInfo()
+		if info.IsDir() {
+			if err := fs.addDir(file.Name, info.ModTime()); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		open := func() io.ReadSeeker {
+			return &reopenReader{open: file.Open, size: int64(file.UncompressedSize64)}
+		}
+
+		if file.Method == zip.Store {
+			offset, err := file.DataOffset()
+			if err != nil {
+				return nil, err
+			}
+			open = func() io.ReadSeeker {
+				return io.NewSectionReader(r, offset, int64(file.UncompressedSize64))
+			}
+		}
+
+		err := fs.addFile(file.Name, int64(file.UncompressedSize64), info.ModTime(), open)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fs.root.updateModTimes()
+	return fs, nil
+}
+
+// Open a zip archive and build a filesystem serving its contents.
+func OpenZipFileSystem(name string) (*ArchiveFileSystem, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	fs, err := NewZipFileSystem(file, stat.Size())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	fs.closer = file.Close
+	return fs, nil
+}
+
+// Counts the bytes read, so that we know where the tar members start.
+type countingReader struct {
+	r     io.Reader
+	count int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// Build a filesystem serving the contents of an uncompressed tar archive. The
+// archive is indexed once; the members are then read directly from it. The
+// directories are handled like in NewZipFileSystem.
+func NewTarFileSystem(r io.ReaderAt, size int64) (*ArchiveFileSystem, error) {
+	counter := &countingReader{r: io.NewSectionReader(r, 0, size)}
+	tr := tar.NewReader(counter)
+
+	fs := newArchiveFileSystem()
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := fs.addDir(header.Name, header.ModTime); err != nil {
+				return nil, err
+			}
+		case tar.TypeReg:
+			offset := counter.count
+			fileSize := header.Size
+			open := func() io.ReadSeeker {
+				return io.NewSectionReader(r, offset, fileSize)
+			}
+			if err := fs.addFile(header.Name, fileSize, header.ModTime, open); err != nil {
+				return nil, err
+			}
+		}
+	}
+	fs.root.updateModTimes()
+	return fs, nil
+}
+
+// Open a tar archive, possibly gzipped, and build a filesystem serving its
+// contents. The gzipped archives are decompressed to a temporary file first,
+// since they cannot be read at random offsets.
+func OpenTarFileSystem(name string) (*ArchiveFileSystem, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(file, magic); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		file.Close()
+		return nil, err
+	}
+
+	closer := file.Close
+	if magic[0] == 0x1f && magic[1] == 0x8b {
+		tmp, err := decompressToTemp(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		file = tmp
+		closer = func() error {
+			err := tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		closer()
+		return nil, err
+	}
+
+	fs, err := NewTarFileSystem(file, stat.Size())
+	if err != nil {
+		closer()
+		return nil, err
+	}
+	fs.closer = closer
+	return fs, nil
+}
+
+func decompressToTemp(file *os.File) (*os.File, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	tmp, err := os.CreateTemp("", "srvutils-tar-")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(tmp, gz); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	return tmp, nil
+}
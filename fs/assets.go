@@ -0,0 +1,159 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var fingerprintRe = regexp.MustCompile(`^.+\.[0-9a-fA-F]{6,}\.[^.]+$`)
+
+type AssetOptions struct {
+	// How long the clients may cache the fingerprinted files; a year if zero
+	MaxAge time.Duration
+
+	// Tell whether the file name contains a content hash, so that the file can
+	// be cached forever; defaults to matching names like "main.3f2a1c.js"
+	IsFingerprinted func(name string) bool
+}
+
+type assetHash struct {
+	modTime time.Time
+	size    int64
+	etag    string
+}
+
+// Serves files with strong ETags derived from their content and answers the
+// conditional requests. The fingerprinted files are marked as immutable, the
+// rest must be revalidated by the clients on every use.
+type AssetServer struct {
+	fs         http.FileSystem
+	fileServer http.Handler
+	opts       AssetOptions
+	hashes     map[string]assetHash
+	mutex      *sync.Mutex
+}
+
+func IsFingerprinted(name string) bool {
+	return fingerprintRe.MatchString(path.Base(name))
+}
+
+// Format the SHA-256 digest of the content as a strong ETag.
+func digestETag(digest []byte) string {
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(digest)[:32])
+}
+
+// The strong ETag of the content held in memory.
+func contentETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return digestETag(sum[:])
+}
+
+// Compute the ETag of the file unless it is known already or the file provides
+// its own. The file is left at its beginning.
+func (s AssetServer) etag(name string, file http.File) (string, error) {
+	if tagged, ok := file.(interface{ ETag() string }); ok {
+		return tagged.ETag(), nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	s.mutex.Lock()
+	hash, ok := s.hashes[name]
+	s.mutex.Unlock()
+	if ok && hash.modTime.Equal(info.ModTime()) && hash.size == info.Size() {
+		return hash.etag, nil
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	etag := digestETag(h.Sum(nil))
+	s.mutex.Lock()
+	s.hashes[name] = assetHash{info.ModTime(), info.Size(), etag}
+	s.mutex.Unlock()
+	return etag, nil
+}
+
+func (s AssetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	upath := r.URL.Path
+	if !strings.HasPrefix(upath, "/") {
+		upath = "/" + upath
+	}
+
+	name := path.Clean(upath)
+	if strings.HasSuffix(upath, "/") {
+		name = path.Join(name, "index.html")
+	}
+
+	file, err := s.fs.Open(name)
+	if err != nil {
+		s.fileServer.ServeHTTP(w, r)
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil || stat.IsDir() {
+		s.fileServer.ServeHTTP(w, r)
+		return
+	}
+
+	etag, err := s.etag(name, file)
+	if err != nil {
+		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	if s.opts.IsFingerprinted(name) {
+		w.Header().Set("Cache-Control",
+			fmt.Sprintf("public, max-age=%d, immutable", int64(s.opts.MaxAge.Seconds())))
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	// The ETag is all we need for the conditional requests; the modification
+	// times of the embedded and virtual files are meaningless
+	http.ServeContent(w, r, name, time.Time{}, file)
+}
+
+func NewAssetServer(fs http.FileSystem, opts AssetOptions) http.Handler {
+	if opts.MaxAge == 0 {
+		opts.MaxAge = 365 * 24 * time.Hour
+	}
+
+	if opts.IsFingerprinted == nil {
+		opts.IsFingerprinted = IsFingerprinted
+	}
+
+	return AssetServer{
+		fs:         fs,
+		fileServer: http.FileServer(fs),
+		opts:       opts,
+		hashes:     make(map[string]assetHash),
+		mutex:      &sync.Mutex{},
+	}
+}
@@ -0,0 +1,272 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+// The estimated memory cost of an entry on top of its contents.
+const cacheEntryOverhead = 256
+
+type CacheOptions struct {
+	// The bound of the total size of the cached contents; 64MB if zero
+	MaxSize int64
+
+	// The files larger than this are read from the wrapped filesystem each
+	// time, only their metadata is cached; an eighth of MaxSize if zero
+	MaxFileSize int64
+
+	// How long an entry stays valid; a minute if zero, forever if negative
+	TTL time.Duration
+}
+
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+
+	// The opens of the files too large to be cached, served from the wrapped
+	// filesystem despite their cached metadata
+	Bypasses uint64
+
+	Evictions uint64
+	Entries   int
+	Size      int64
+}
+
+type cacheEntry struct {
+	name      string
+	info      os.FileInfo
+	data      []byte
+	entries   []os.FileInfo
+	notExist  bool
+	cached    bool
+	cost      int64
+	timestamp time.Time
+}
+
+// An open file served from the cache.
+type CachedFile struct {
+	*bytes.Reader
+	entry *cacheEntry
+}
+
+// A filesystem caching the contents, the metadata and the listings of the
+// wrapped filesystem in memory. The least recently used entries are evicted
+// when the cache grows over its bound.
+type CachingFileSystem struct {
+	fs      http.FileSystem
+	opts    CacheOptions
+	entries map[string]*list.Element
+	lru     *list.List
+	stats   CacheStats
+	mutex   *sync.Mutex
+}
+
+func (f *CachedFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, fmt.Errorf("cannot Readdir from a file")
+}
+
+func (f *CachedFile) Stat() (os.FileInfo, error) {
+	return f.entry.info, nil
+}
+
+func (f *CachedFile) Close() error {
+	return nil
+}
+
+func (fs *CachingFileSystem) lookup(name string) *cacheEntry {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	element, ok := fs.entries[name]
+	if !ok {
+		fs.stats.Misses++
+		return nil
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if fs.opts.TTL > 0 && time.Since(entry.timestamp) > fs.opts.TTL {
+		fs.remove(element)
+		fs.stats.Misses++
+		return nil
+	}
+
+	fs.lru.MoveToFront(element)
+	if entry.cached || entry.notExist {
+		fs.stats.Hits++
+	} else {
+		fs.stats.Bypasses++
+	}
+	return entry
+}
+
+func (fs *CachingFileSystem) remove(element *list.Element) {
+	entry := fs.lru.Remove(element).(*cacheEntry)
+	delete(fs.entries, entry.name)
+	fs.stats.Size -= entry.cost
+}
+
+func (fs *CachingFileSystem) store(entry *cacheEntry) {
+	entry.cost = int64(len(entry.data)) + cacheEntryOverhead*int64(len(entry.entries)+1)
+	entry.timestamp = time.Now()
+
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if element, ok := fs.entries[entry.name]; ok {
+		fs.remove(element)
+	}
+
+	if entry.cost > fs.opts.MaxSize {
+		return
+	}
+
+	fs.entries[entry.name] = fs.lru.PushFront(entry)
+	fs.stats.Size += entry.cost
+
+	for fs.stats.Size > fs.opts.MaxSize {
+		fs.remove(fs.lru.Back())
+		fs.stats.Evictions++
+	}
+}
+
+// Fetch the file from the wrapped filesystem and cache whatever can be cached.
+// The file is returned only if its contents could not be cached.
+func (fs *CachingFileSystem) fetch(name string) (*cacheEntry, http.File, error) {
+	file, err := fs.fs.Open(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			fs.store(&cacheEntry{name: name, notExist: true})
+		}
+		return nil, nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	entry := &cacheEntry{name: name, info: info}
+	switch {
+	case info.IsDir():
+		entries, err := file.Readdir(-1)
+		file.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		sortEntries(entries)
+		entry.entries = entries
+		entry.cached = true
+	case info.Size() <= fs.opts.MaxFileSize:
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		entry.data = data
+		entry.cached = true
+	}
+
+	fs.store(entry)
+	if entry.cached {
+		return entry, nil, nil
+	}
+	return entry, file, nil
+}
+
+func (fs *CachingFileSystem) Open(name string) (http.File, error) {
+	name = path.Clean("/" + name)
+	entry := fs.lookup(name)
+	if entry == nil {
+		var file http.File
+		var err error
+		entry, file, err = fs.fetch(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if file != nil {
+			return file, nil
+		}
+	}
+
+	if entry.notExist {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	if !entry.cached {
+		return fs.fs.Open(name)
+	}
+
+	// The listings get sorted in place by their readers, ie. http.FileServer,
+	// so each handle gets its own copy
+	if entry.info.IsDir() {
+		entries := append(make([]os.FileInfo, 0, len(entry.entries)), entry.entries...)
+		return &dirHandle{info: entry.info, entries: entries}, nil
+	}
+	return &CachedFile{Reader: bytes.NewReader(entry.data), entry: entry}, nil
+}
+
+// Drop the file from the cache; the whole cache if the name is empty.
+func (fs *CachingFileSystem) Invalidate(name string) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if name == "" {
+		fs.entries = make(map[string]*list.Element)
+		fs.lru.Init()
+		fs.stats.Size = 0
+		return
+	}
+
+	if element, ok := fs.entries[path.Clean("/"+name)]; ok {
+		fs.remove(element)
+	}
+}
+
+func (fs *CachingFileSystem) Stats() CacheStats {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	stats := fs.stats
+	stats.Entries = len(fs.entries)
+	return stats
+}
+
+// Wrap the filesystem with an in-memory cache.
+func NewCachingFileSystem(fs http.FileSystem, opts CacheOptions) *CachingFileSystem {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 64 << 20
+	}
+
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = opts.MaxSize / 8
+	}
+
+	if opts.TTL == 0 {
+		opts.TTL = time.Minute
+	}
+
+	return &CachingFileSystem{
+		fs:      fs,
+		opts:    opts,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		mutex:   &sync.Mutex{},
+	}
+}
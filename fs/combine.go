@@ -8,10 +8,15 @@
 package fs
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"net/http"
 	"os"
 	"path"
+	"sort"
 	"strings"
+	"time"
 )
 
 type ComposedEntry struct {
@@ -19,9 +24,16 @@ type ComposedEntry struct {
 	Fs         http.FileSystem
 }
 
+// A directory of the composed filesystem. Its listing merges the entries of
+// the underlying directory with the mount points that live directly below it.
+// Mount points shadow the underlying entries of the same name.
 type ComposedDir struct {
 	http.File
-	extraEntries []string
+	fs      ComposedFileSystem
+	dirPath string
+	entries []os.FileInfo
+	loaded  bool
+	offset  int
 }
 
 type ComposedFileSystem struct {
@@ -29,50 +41,251 @@ type ComposedFileSystem struct {
 	mountPoints map[string]http.FileSystem
 }
 
-func (d ComposedDir) Readdir(count int) ([]os.FileInfo, error) {
-	entries, err := d.File.Readdir(count)
+// FileInfo reported under a different name, ie. for a mount point, since the
+// underlying filesystem does not know the name under which it has been mounted.
+type renamedInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (i renamedInfo) Name() string {
+	return i.name
+}
+
+// A file whose Stat reports a different name.
+type renamedFile struct {
+	http.File
+	name string
+}
+
+func (f renamedFile) Stat() (os.FileInfo, error) {
+	info, err := f.File.Stat()
 	if err != nil {
-		return entries, nil
+		return nil, err
 	}
+	return renamedInfo{info, f.name}, nil
+}
+
+// FileInfo of an intermediate directory leading to a nested mount point that
+// does not exist in the root filesystem.
+type dirInfo struct {
+	name string
+}
 
-	for _, name := range d.extraEntries {
-		entries = append(entries, VirtualFile{name, 0, true, nil})
+func (i dirInfo) Name() string       { return i.name }
+func (i dirInfo) Size() int64        { return 0 }
+func (i dirInfo) Mode() os.FileMode  { return os.ModeDir | 0555 }
+func (i dirInfo) ModTime() time.Time { return time.Time{} }
+func (i dirInfo) IsDir() bool        { return true }
+func (i dirInfo) Sys() interface{}   { return nil }
+
+// An empty directory standing in for the intermediate ones.
+type syntheticDir struct {
+	*bytes.Reader
+	info dirInfo
+}
+
+func (d syntheticDir) Readdir(count int) ([]os.FileInfo, error) {
+	if count > 0 {
+		return nil, io.EOF
 	}
-	return entries, nil
+	return nil, nil
 }
 
-func (fs ComposedFileSystem) Open(name string) (http.File, error) {
-	if name == "/" {
-		file, err := fs.root.Open(name)
+func (d syntheticDir) Stat() (os.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d syntheticDir) Close() error {
+	return nil
+}
+
+func newSyntheticDir(name string) syntheticDir {
+	return syntheticDir{bytes.NewReader(nil), dirInfo{path.Base(name)}}
+}
+
+func (d *ComposedDir) load() error {
+	if d.loaded {
+		return nil
+	}
+
+	merged := make(map[string]os.FileInfo)
+	entries, err := d.File.Readdir(-1)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		merged[entry.Name()] = entry
+	}
 
+	mounts, dirs := d.fs.childMounts(d.dirPath)
+	for _, name := range dirs {
+		if info, ok := merged[name]; ok && info.IsDir() {
+			continue
+		}
+		merged[name] = dirInfo{name}
+	}
+
+	for name, subFs := range mounts {
+		info, err := statRoot(subFs)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		merged[name] = renamedInfo{info, name}
+	}
+
+	d.entries = make([]os.FileInfo, 0, len(merged))
+	for _, info := range merged {
+		d.entries = append(d.entries, info)
+	}
+	sortEntries(d.entries)
+	d.loaded = true
+	return nil
+}
+
+func (d *ComposedDir) Readdir(count int) ([]os.FileInfo, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	return readdirPage(d.entries, &d.offset, count)
+}
+
+// Return the next page of a directory listing following the semantics of
+// os.File.Readdir.
+func readdirPage(entries []os.FileInfo, offset *int, count int) ([]os.FileInfo, error) {
+	remaining := entries[*offset:]
+	if count <= 0 {
+		*offset = len(entries)
+		return remaining, nil
+	}
 
-		var subDirs []string
-		for name, _ := range fs.mountPoints {
-			subDirs = append(subDirs, name)
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	if count > len(remaining) {
+		count = len(remaining)
+	}
+	*offset += count
+	return remaining[:count], nil
+}
+
+func sortEntries(entries []os.FileInfo) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+}
+
+func statRoot(fs http.FileSystem) (os.FileInfo, error) {
+	file, err := fs.Open("/")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return file.Stat()
+}
+
+// Find the mount points placed directly in the given directory and the names
+// of the intermediate directories leading to the nested ones.
+func (fs ComposedFileSystem) childMounts(dir string) (map[string]http.FileSystem, []string) {
+	mounts := make(map[string]http.FileSystem)
+	var dirs []string
+	seen := make(map[string]bool)
+
+	prefix := strings.TrimPrefix(dir, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	for mountPoint, subFs := range fs.mountPoints {
+		if !strings.HasPrefix(mountPoint, prefix) {
+			continue
+		}
+		rest := mountPoint[len(prefix):]
+		if idx := strings.Index(rest, "/"); idx != -1 {
+			name := rest[:idx]
+			if !seen[name] {
+				seen[name] = true
+				dirs = append(dirs, name)
+			}
+			continue
 		}
-		return ComposedDir{file, subDirs}, nil
+		mounts[rest] = subFs
 	}
+	return mounts, dirs
+}
 
-	components := strings.Split(name, "/")
+// Find the mount point that is the longest prefix of the given path.
+func (fs ComposedFileSystem) findMount(name string) (string, http.FileSystem) {
+	rel := strings.TrimPrefix(name, "/")
+	for {
+		if subFs, ok := fs.mountPoints[rel]; ok {
+			return rel, subFs
+		}
+		idx := strings.LastIndex(rel, "/")
+		if idx == -1 {
+			return "", nil
+		}
+		rel = rel[:idx]
+	}
+}
 
-	if len(components) < 2 || !strings.HasPrefix(name, "/") {
+func (fs ComposedFileSystem) Open(name string) (http.File, error) {
+	if !strings.HasPrefix(name, "/") {
 		return fs.root.Open(name)
 	}
+	name = path.Clean(name)
+
+	if mountPoint, subFs := fs.findMount(name); subFs != nil {
+		rest := strings.TrimPrefix(name[1:], mountPoint)
+		if rest != "" {
+			return subFs.Open(rest)
+		}
+
+		file, err := subFs.Open("/")
+		if err != nil {
+			return nil, err
+		}
+		return renamedFile{file, path.Base(mountPoint)}, nil
+	}
 
-	if subFs, ok := fs.mountPoints[components[1]]; ok {
-		return subFs.Open(path.Join(components[2:]...))
+	mounts, dirs := fs.childMounts(name)
+	if len(mounts) == 0 && len(dirs) == 0 {
+		return fs.root.Open(name)
 	}
 
-	return fs.root.Open(name)
+	file, err := fs.root.Open(name)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		return &ComposedDir{File: newSyntheticDir(name), fs: fs, dirPath: name}, nil
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if !stat.IsDir() {
+		file.Close()
+		return &ComposedDir{File: newSyntheticDir(name), fs: fs, dirPath: name}, nil
+	}
+	return &ComposedDir{File: file, fs: fs, dirPath: name}, nil
 }
 
+// Build a new composed filesystem. The mount points are slash-separated paths
+// relative to the root of the filesystem, ie. "node_modules" or "lib/vendor".
 func NewComposedFileSystem(root http.FileSystem, entries []ComposedEntry) http.FileSystem {
 	mountPoints := make(map[string]http.FileSystem)
 	for _, entry := range entries {
-		mountPoints[entry.MountPoint] = entry.Fs
+		mountPoint := strings.Trim(path.Clean("/"+entry.MountPoint), "/")
+		if mountPoint == "" {
+			continue
+		}
+		mountPoints[mountPoint] = entry.Fs
 	}
 	return ComposedFileSystem{root, mountPoints}
 }
@@ -0,0 +1,282 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type CompressionOptions struct {
+	// Compress the files that have no precompressed variant with gzip on the
+	// first request and keep the result in memory
+	OnTheFly bool
+
+	// Files smaller than this are not compressed on the fly, since gzip would
+	// make them bigger; 1KB if zero, compress all of them if negative
+	MinSize int64
+}
+
+type compressedEntry struct {
+	modTime time.Time
+	size    int64
+	data    []byte
+}
+
+// Serves the precompressed variants of the files if the client accepts them.
+// For a file named "main.js" it looks for:
+//
+//   - "main.js.br" if the client accepts brotli
+//   - "main.js.gz" if the client accepts gzip
+//   - the gzipped content vfsgen embedded at generate time
+//   - the content compressed on the fly and cached, if enabled
+//
+// and falls back to the identity encoding.
+type CompressedFileServer struct {
+	fs         http.FileSystem
+	fileServer http.Handler
+	opts       CompressionOptions
+	cache      map[string]compressedEntry
+	mutex      *sync.Mutex
+}
+
+// A value of a header like Accept-Encoding or Accept-Language with its quality.
+type qualityValue struct {
+	value string
+	q     float64
+}
+
+// Parse the comma-separated lists of values with optional quality parameters,
+// ie. "br;q=1.0, gzip;q=0.8". The values are sorted by their quality, keeping
+// the order of the header for the ties.
+func parseQualityList(headers ...string) []qualityValue {
+	var values []qualityValue
+	for _, header := range headers {
+		for _, part := range strings.Split(header, ",") {
+			fields := strings.Split(part, ";")
+			value := strings.TrimSpace(fields[0])
+			if value == "" {
+				continue
+			}
+
+			q := 1.0
+			for _, param := range fields[1:] {
+				param = strings.TrimSpace(param)
+				if strings.HasPrefix(param, "q=") {
+					if val, err := strconv.ParseFloat(param[2:], 64); err == nil {
+						q = val
+					}
+				}
+			}
+			values = append(values, qualityValue{value, q})
+		}
+	}
+
+	sort.SliceStable(values, func(i, j int) bool { return values[i].q > values[j].q })
+	return values
+}
+
+func acceptedEncodings(header string) map[string]float64 {
+	encodings := make(map[string]float64)
+	for _, value := range parseQualityList(header) {
+		name := strings.ToLower(value.value)
+		if _, ok := encodings[name]; !ok {
+			encodings[name] = value.q
+		}
+	}
+	return encodings
+}
+
+// Get the quality of the encoding; the identity is the last resort unless
+// listed explicitly.
+func encodingQuality(encodings map[string]float64, name string) float64 {
+	if q, ok := encodings[name]; ok {
+		return q
+	}
+	if name == "identity" {
+		return 0
+	}
+	return encodings["*"]
+}
+
+func isCompressible(contentType string) bool {
+	if strings.HasPrefix(contentType, "text/") {
+		return true
+	}
+
+	for _, kind := range []string{"javascript", "json", "xml", "svg", "wasm"} {
+		if strings.Contains(contentType, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// Figure out the content type from the extension or by sniffing the content of
+// the file.
+func detectContentType(name string, file http.File) string {
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		return ctype
+	}
+
+	var buf [512]byte
+	n, _ := io.ReadFull(file, buf[:])
+	file.Seek(0, io.SeekStart)
+	return http.DetectContentType(buf[:n])
+}
+
+func (s CompressedFileServer) serveVariant(w http.ResponseWriter, r *http.Request,
+	name, encoding string) bool {
+
+	file, err := s.fs.Open(name)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil || stat.IsDir() {
+		return false
+	}
+
+	w.Header().Set("Content-Encoding", encoding)
+	http.ServeContent(w, r, name, stat.ModTime(), file)
+	return true
+}
+
+func (s CompressedFileServer) compress(name string, file http.File) ([]byte, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	entry, ok := s.cache[name]
+	s.mutex.Unlock()
+	if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry.data, nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := io.Copy(writer, file); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	s.cache[name] = compressedEntry{info.ModTime(), info.Size(), buf.Bytes()}
+	s.mutex.Unlock()
+	return buf.Bytes(), nil
+}
+
+// Serve the precompressed variant, the gzipped content provided by the file
+// itself, or the file compressed on the fly, whichever is available first.
+func (s CompressedFileServer) serveGzip(w http.ResponseWriter, r *http.Request, name string,
+	file http.File, stat os.FileInfo, ctype string) bool {
+
+	if s.serveVariant(w, r, name+".gz", "gzip") {
+		return true
+	}
+
+	if gz, ok := file.(interface{ GzipBytes() []byte }); ok {
+		w.Header().Set("Content-Encoding", "gzip")
+		http.ServeContent(w, r, name, stat.ModTime(), bytes.NewReader(gz.GzipBytes()))
+		return true
+	}
+
+	if s.opts.OnTheFly && stat.Size() >= s.opts.MinSize && isCompressible(ctype) {
+		data, err := s.compress(name, file)
+		if err == nil {
+			w.Header().Set("Content-Encoding", "gzip")
+			http.ServeContent(w, r, name, stat.ModTime(), bytes.NewReader(data))
+			return true
+		}
+		file.Seek(0, io.SeekStart)
+	}
+	return false
+}
+
+func (s CompressedFileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	upath := r.URL.Path
+	if !strings.HasPrefix(upath, "/") {
+		upath = "/" + upath
+	}
+
+	name := path.Clean(upath)
+	if strings.HasSuffix(upath, "/") {
+		name = path.Join(name, "index.html")
+	}
+
+	file, err := s.fs.Open(name)
+	if err != nil {
+		s.fileServer.ServeHTTP(w, r)
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil || stat.IsDir() {
+		s.fileServer.ServeHTTP(w, r)
+		return
+	}
+
+	ctype := detectContentType(name, file)
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.Header().Set("Content-Type", ctype)
+
+	// Try the encodings in the order of the client's preference; the ties
+	// go to the better compression
+	encodings := acceptedEncodings(r.Header.Get("Accept-Encoding"))
+	candidates := []string{"br", "gzip", "identity"}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return encodingQuality(encodings, candidates[i]) > encodingQuality(encodings, candidates[j])
+	})
+
+	for _, encoding := range candidates {
+		if encoding == "identity" || encodingQuality(encodings, encoding) <= 0 {
+			break
+		}
+
+		if encoding == "br" && s.serveVariant(w, r, name+".br", "br") {
+			return
+		}
+
+		if encoding == "gzip" && s.serveGzip(w, r, name, file, stat, ctype) {
+			return
+		}
+	}
+
+	http.ServeContent(w, r, name, stat.ModTime(), file)
+}
+
+func NewCompressedFileServer(fs http.FileSystem, opts CompressionOptions) http.Handler {
+	if opts.MinSize == 0 {
+		opts.MinSize = 1024
+	}
+
+	return CompressedFileServer{
+		fs:         fs,
+		fileServer: http.FileServer(fs),
+		opts:       opts,
+		cache:      make(map[string]compressedEntry),
+		mutex:      &sync.Mutex{},
+	}
+}
@@ -0,0 +1,88 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+//go:build dev
+// +build dev
+
+package fs
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Set when the binary is built with the dev tag.
+const DevMode = true
+
+// Serves the files that exist in the filesystem and proxies everything else,
+// including the websocket upgrades for hot module replacement, to the frontend
+// dev server.
+type DevProxyHandler struct {
+	fs             http.FileSystem
+	proxy          http.Handler
+	wrappedHandler http.Handler
+}
+
+func (h DevProxyHandler) exists(name string) bool {
+	file, err := h.fs.Open(name)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	if !stat.IsDir() {
+		return true
+	}
+
+	index, err := h.fs.Open(path.Join(name, "index.html"))
+	if err != nil {
+		return false
+	}
+	index.Close()
+	return true
+}
+
+func (h DevProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	upath := r.URL.Path
+	if !strings.HasPrefix(upath, "/") {
+		upath = "/" + upath
+	}
+
+	isUpgrade := strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+	if !isUpgrade && h.exists(path.Clean(upath)) {
+		h.wrappedHandler.ServeHTTP(w, r)
+		return
+	}
+	h.proxy.ServeHTTP(w, r)
+}
+
+// Build a new dev proxy handler. The files found in the filesystem are served
+// by the handler, or by http.FileServer if it is nil; the rest is proxied to
+// the dev server at the target URL, ie. "http://localhost:5173". In the builds
+// without the dev tag, the handler is returned as it is and nothing is
+// proxied.
+func NewDevProxyHandler(fs http.FileSystem, target string, handler http.Handler) (http.Handler, error) {
+	if handler == nil {
+		handler = http.FileServer(fs)
+	}
+
+	targetUrl, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(targetUrl)
+	return DevProxyHandler{fs, proxy, handler}, nil
+}
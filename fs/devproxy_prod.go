@@ -0,0 +1,27 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+//go:build !dev
+// +build !dev
+
+package fs
+
+import (
+	"net/http"
+)
+
+// Set when the binary is built with the dev tag.
+const DevMode = false
+
+// Outside of the dev builds there is no dev server to proxy to, so the files
+// are served by the handler, or by http.FileServer if it is nil.
+func NewDevProxyHandler(fs http.FileSystem, target string, handler http.Handler) (http.Handler, error) {
+	if handler == nil {
+		handler = http.FileServer(fs)
+	}
+	return handler, nil
+}
@@ -0,0 +1,140 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"path"
+)
+
+// Replaces the bodies of the error responses of the wrapped handler with the
+// configured pages, ie. 404 with "/errors/404.html", keeping the status codes.
+type ErrorPagesHandler struct {
+	fs             http.FileSystem
+	pages          map[int]string
+	wrappedHandler http.Handler
+}
+
+// Intercepts the error responses that have a page configured.
+type errorPageWriter struct {
+	http.ResponseWriter
+	handler     ErrorPagesHandler
+	status      int
+	intercepted bool
+	wroteHeader bool
+}
+
+func (w *errorPageWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if name, ok := w.handler.pages[status]; ok {
+		if file, err := w.handler.fs.Open(name); err == nil {
+			file.Close()
+			w.status = status
+			w.intercepted = true
+			return
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *errorPageWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.intercepted {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush the response unless it is being replaced, ie. for LiveReload.
+func (w *errorPageWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && !w.intercepted {
+		flusher.Flush()
+	}
+}
+
+// Hand the connection over to the wrapped handler, ie. for the websocket
+// upgrades; the response is not intercepted anymore.
+func (w *errorPageWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	w.wroteHeader = true
+	return hijacker.Hijack()
+}
+
+// Expose the original writer to http.ResponseController.
+func (w *errorPageWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (handler ErrorPagesHandler) servePage(w http.ResponseWriter, r *http.Request, status int) {
+	header := w.Header()
+	for _, name := range []string{"Content-Length", "Content-Encoding", "Content-Range",
+		"ETag", "Last-Modified"} {
+		header.Del(name)
+	}
+
+	name := handler.pages[status]
+	file, err := handler.fs.Open(name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("%d %s", status, http.StatusText(status)), status)
+		return
+	}
+	defer file.Close()
+
+	ctype := mime.TypeByExtension(path.Ext(name))
+	if ctype == "" {
+		ctype = "text/html; charset=utf-8"
+	}
+	header.Set("Content-Type", ctype)
+	header.Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if r.Method != http.MethodHead {
+		io.Copy(w, file)
+	}
+}
+
+func (handler ErrorPagesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writer := &errorPageWriter{ResponseWriter: w, handler: handler}
+	handler.wrappedHandler.ServeHTTP(writer, r)
+
+	if writer.intercepted {
+		handler.servePage(w, r, writer.status)
+	}
+}
+
+// Build a new error page handler. The pages map the status codes to the paths
+// of the pages within the filesystem. If the wrapped handler is nil, the files
+// are served from the filesystem with http.FileServer, which reports missing
+// files with 404, inaccessible ones with 403, and other errors with 500.
+func NewErrorPagesHandler(fs http.FileSystem, pages map[int]string,
+	handler http.Handler) http.Handler {
+
+	if handler == nil {
+		handler = http.FileServer(fs)
+	}
+	return ErrorPagesHandler{fs, pages, handler}
+}
@@ -8,8 +8,11 @@
 package fs
 
 import (
+	"fmt"
 	"net/http"
 	"os"
+	"path"
+	"regexp"
 	"strings"
 )
 
@@ -19,23 +22,167 @@ type FsNode struct {
 
 type FilteredDir struct {
 	http.File
-	node FsNode
+	fs      FilteredFileSystem
+	dirPath string
 }
 
 type FilteredFileSystem struct {
 	fs        http.FileSystem
 	whitelist FsNode
+	includes  []filterRule
+	excludes  []filterRule
+	entries   []string
+	serveAll  bool
 }
 
-func (d FilteredDir) Readdir(count int) ([]os.FileInfo, error) {
-	lst, err := d.File.Readdir(count)
-	var filtered []os.FileInfo
-	for _, el := range lst {
-		if _, ok := d.node.Children[el.Name()]; ok {
-			filtered = append(filtered, el)
+// Filesystems that can report the entries of their whitelist that do not match
+// anything in the underlying filesystem.
+type WhitelistChecker interface {
+	UnmatchedEntries() ([]string, error)
+}
+
+// A glob or a regular expression entry of the whitelist.
+type filterRule struct {
+	entry string
+	glob  []string
+	re    *regexp.Regexp
+}
+
+func (r filterRule) matches(components []string) bool {
+	if r.re != nil {
+		return r.re.MatchString("/" + strings.Join(components, "/"))
+	}
+	return matchGlob(r.glob, components)
+}
+
+// Check whether the rule may match something below the given directory.
+func (r filterRule) matchesBelow(components []string) bool {
+	if r.re != nil {
+		return true
+	}
+	return matchGlobBelow(r.glob, components)
+}
+
+// Match the path components against the glob segments; a "**" segment matches
+// zero or more path components. The segments are validated by parseRule, so
+// path.Match cannot fail.
+func matchGlob(glob []string, components []string) bool {
+	if len(glob) == 0 {
+		return len(components) == 0
+	}
+
+	if glob[0] == "**" {
+		for i := 0; i <= len(components); i++ {
+			if matchGlob(glob[1:], components[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(components) == 0 {
+		return false
+	}
+
+	if ok, _ := path.Match(glob[0], components[0]); !ok {
+		return false
+	}
+	return matchGlob(glob[1:], components[1:])
+}
+
+func matchGlobBelow(glob []string, components []string) bool {
+	if len(glob) == 0 {
+		return false
+	}
+
+	if glob[0] == "**" {
+		return true
+	}
+
+	if len(components) == 0 {
+		return true
+	}
+
+	if ok, _ := path.Match(glob[0], components[0]); !ok {
+		return false
+	}
+	return matchGlobBelow(glob[1:], components[1:])
+}
+
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return []string{}
+	}
+	return strings.Split(p, "/")
+}
+
+func (fs FilteredFileSystem) excluded(components []string) bool {
+	for i := 0; i <= len(components); i++ {
+		for _, rule := range fs.excludes {
+			if rule.matches(components[:i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (fs FilteredFileSystem) included(components []string, isDir bool) bool {
+	if fs.serveAll {
+		return true
+	}
+
+	if hasComponents(fs.whitelist, components) {
+		return true
+	}
+
+	for _, rule := range fs.includes {
+		if rule.matches(components) {
+			return true
+		}
+		if isDir && rule.matchesBelow(components) {
+			return true
+		}
+	}
+	return false
+}
+
+func (fs FilteredFileSystem) allowed(components []string, isDir bool) bool {
+	return !fs.excluded(components) && fs.included(components, isDir)
+}
+
+// Read the entries of the directory that the function keeps; the function gets
+// the path components of each entry.
+func filterDir(file http.File, dirPath string, count int,
+	keep func(components []string, info os.FileInfo) (bool, error)) ([]os.FileInfo, error) {
+
+	dirComponents := splitPath(dirPath)
+	for {
+		lst, err := file.Readdir(count)
+		var filtered []os.FileInfo
+		for _, el := range lst {
+			components := append(dirComponents[:len(dirComponents):len(dirComponents)], el.Name())
+			ok, keepErr := keep(components, el)
+			if keepErr != nil {
+				return nil, keepErr
+			}
+			if ok {
+				filtered = append(filtered, el)
+			}
+		}
+
+		// Do not report an empty page unless the directory is exhausted
+		if len(filtered) != 0 || err != nil || count <= 0 || len(lst) == 0 {
+			return filtered, err
 		}
 	}
-	return filtered, err
+}
+
+func (d FilteredDir) Readdir(count int) ([]os.FileInfo, error) {
+	return filterDir(d.File, d.dirPath, count, func(components []string, info os.FileInfo) (bool, error) {
+		return d.fs.allowed(components, info.IsDir()), nil
+	})
 }
 
 func (fs FilteredFileSystem) Open(path string) (http.File, error) {
@@ -43,14 +190,15 @@ func (fs FilteredFileSystem) Open(path string) (http.File, error) {
 		return nil, os.ErrNotExist
 	}
 
-	components := strings.Split(path, "/")
+	components := splitPath(path)
 
-	if path == "/" {
-		components = components[1:]
+	if fs.excluded(components) {
+		return nil, os.ErrNotExist
 	}
 
-	if !hasComponents(fs.whitelist, components[1:]) {
-
+	// Without patterns, the whitelist tree alone decides, so we may refuse
+	// before touching the underlying filesystem
+	if !fs.serveAll && len(fs.includes) == 0 && !hasComponents(fs.whitelist, components) {
 		return nil, os.ErrNotExist
 	}
 
@@ -61,16 +209,87 @@ func (fs FilteredFileSystem) Open(path string) (http.File, error) {
 
 	stat, err := file.Stat()
 	if err != nil {
+		file.Close()
 		return nil, err
 	}
 
+	if !fs.included(components, stat.IsDir()) {
+		file.Close()
+		return nil, os.ErrNotExist
+	}
+
 	if stat.IsDir() {
-		node, _ := getNode(fs.whitelist, components[1:])
-		return FilteredDir{file, node}, nil
+		return FilteredDir{file, fs, "/" + strings.Join(components, "/")}, nil
 	}
 	return file, nil
 }
 
+// Walk the underlying filesystem and list the whitelist entries that match
+// nothing. Useful for catching the stale entries at generate time. The
+// blacklists have none, since their entries need not match anything.
+func (fs FilteredFileSystem) UnmatchedEntries() ([]string, error) {
+	if fs.serveAll {
+		return nil, nil
+	}
+
+	matched := make(map[string]bool)
+	var walk func(components []string) error
+	walk = func(components []string) error {
+		file, err := fs.fs.Open("/" + strings.Join(components, "/"))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		entries, err := file.Readdir(-1)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			child := append(components[:len(components):len(components)], entry.Name())
+			for _, rule := range fs.includes {
+				if rule.matches(child) {
+					matched[rule.entry] = true
+				}
+			}
+			for _, rule := range fs.excludes {
+				if rule.matches(child) {
+					matched[rule.entry] = true
+				}
+			}
+
+			if entry.IsDir() && fs.included(child, true) {
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := walk([]string{}); err != nil {
+		return nil, err
+	}
+
+	var unmatched []string
+	for _, entry := range fs.entries {
+		if matched[entry] {
+			continue
+		}
+
+		if !strings.HasPrefix(entry, "!") && !isPattern(entry) {
+			file, err := fs.fs.Open(path.Clean("/" + entry))
+			if err == nil {
+				file.Close()
+				continue
+			}
+		}
+		unmatched = append(unmatched, entry)
+	}
+	return unmatched, nil
+}
+
 func addPath(node FsNode, components []string) {
 	if len(components) == 0 {
 		return
@@ -95,28 +314,134 @@ func hasComponents(node FsNode, components []string) bool {
 	return hasComponents(child, components[1:])
 }
 
-func getNode(node FsNode, components []string) (FsNode, error) {
-	if len(components) == 0 {
-		return node, nil
+// Check whether the whitelist entry is explicitly marked as a pattern.
+func isPattern(entry string) bool {
+	return strings.HasPrefix(entry, "re:") || strings.HasPrefix(entry, "glob:")
+}
+
+// Parse a pattern; the ones not prefixed with "re:" are globs, the "glob:"
+// prefix is optional.
+func parseRule(entry string) (filterRule, error) {
+	if strings.HasPrefix(entry, "re:") {
+		re, err := regexp.Compile(entry[3:])
+		if err != nil {
+			return filterRule{}, fmt.Errorf("invalid pattern %q: %w", entry, err)
+		}
+		return filterRule{entry: entry, re: re}, nil
 	}
 
-	child, ok := node.Children[components[0]]
-	if !ok {
-		return FsNode{}, nil
+	glob := splitPath(strings.TrimPrefix(entry, "glob:"))
+	if len(glob) == 0 {
+		return filterRule{}, fmt.Errorf("empty pattern %q", entry)
+	}
+
+	for _, segment := range glob {
+		if segment == "**" {
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return filterRule{}, fmt.Errorf("invalid pattern %q: %w", entry, err)
+		}
 	}
-	return getNode(child, components[1:])
+	return filterRule{entry: entry, glob: glob}, nil
 }
 
-// Build new filtered filesystem. The whitelisted paths must start with a leading slash.
-func NewFilteredFileSystem(fs http.FileSystem, whitelist []string) http.FileSystem {
-	filteredFs := FilteredFileSystem{fs, FsNode{}}
+// A rule matching the exact path, even if it contains the glob metacharacters.
+func literalRule(entry string, p string) filterRule {
+	escaper := strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`)
+	glob := splitPath(p)
+	for i, segment := range glob {
+		glob[i] = escaper.Replace(segment)
+	}
+	return filterRule{entry: entry, glob: glob}
+}
+
+// Parse the patterns, ie. "**/*.js" or "re:\.js$".
+func parseRules(patterns []string) ([]filterRule, error) {
+	var rules []filterRule
+	for _, pattern := range patterns {
+		rule, err := parseRule(pattern)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Build new filtered filesystem. The entries are exact paths, ie.
+// "/index.html", unless they are explicitly marked as patterns:
+//
+//   - a glob if prefixed with "glob:", where "**" matches any number of
+//     directories, ie. "glob:/node_modules/onsenui/css/**/*.css"; the globs
+//     are always anchored at the root
+//   - a regular expression matched against the full path if prefixed with
+//     "re:", ie. "re:^/fonts/.*\.woff2?$"; since a regular expression cannot
+//     tell which directories lead to a match, all of them are listed
+//
+// Entries prefixed with "!" exclude the matching paths and everything below
+// them, ie. "!glob:/node_modules/**/*.map". An invalid pattern is an error.
+func NewWhitelistedFileSystem(fs http.FileSystem, whitelist []string) (http.FileSystem, error) {
+	filteredFs := FilteredFileSystem{fs: fs}
 	filteredFs.whitelist.Children = make(map[string]FsNode)
-	for _, path := range whitelist {
-		if !strings.HasPrefix(path, "/") {
-			continue
+	for _, entry := range whitelist {
+		negate := strings.HasPrefix(entry, "!")
+		pattern := strings.TrimPrefix(entry, "!")
+
+		var rule filterRule
+		if isPattern(pattern) {
+			var err error
+			rule, err = parseRule(pattern)
+			if err != nil {
+				return nil, err
+			}
+			rule.entry = entry
+		} else {
+			exact := path.Clean("/" + pattern)
+			if !negate {
+				filteredFs.entries = append(filteredFs.entries, entry)
+				addPath(filteredFs.whitelist, splitPath(exact))
+				continue
+			}
+			rule = literalRule(entry, exact)
+		}
+
+		filteredFs.entries = append(filteredFs.entries, entry)
+		if negate {
+			filteredFs.excludes = append(filteredFs.excludes, rule)
+		} else {
+			filteredFs.includes = append(filteredFs.includes, rule)
 		}
-		components := strings.Split(path, "/")
-		addPath(filteredFs.whitelist, components[1:])
+	}
+	return filteredFs, nil
+}
+
+// Same as NewWhitelistedFileSystem, but, like regexp.MustCompile, it panics if
+// an entry is not a valid pattern, so that the whitelists can still initialize
+// the package-level variables.
+func NewFilteredFileSystem(fs http.FileSystem, whitelist []string) http.FileSystem {
+	filteredFs, err := NewWhitelistedFileSystem(fs, whitelist)
+	if err != nil {
+		panic("fs: NewFilteredFileSystem: " + err.Error())
 	}
 	return filteredFs
 }
+
+// Build new filtered filesystem that serves everything except for the
+// blacklisted paths and everything below them. The entries are globs, ie.
+// "**/*.map" or "**/.git", or regular expressions if prefixed with "re:", ie.
+// "re:\.ts$". An invalid entry is an error, rather than a hole in the
+// blacklist.
+func NewBlacklistedFileSystem(fs http.FileSystem, blacklist []string) (http.FileSystem, error) {
+	filteredFs := FilteredFileSystem{fs: fs, serveAll: true}
+	filteredFs.whitelist.Children = make(map[string]FsNode)
+	for _, path := range blacklist {
+		rule, err := parseRule(path)
+		if err != nil {
+			return nil, err
+		}
+		filteredFs.entries = append(filteredFs.entries, path)
+		filteredFs.excludes = append(filteredFs.excludes, rule)
+	}
+	return filteredFs, nil
+}
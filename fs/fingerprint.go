@@ -0,0 +1,310 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Maps the logical names of the files to their fingerprinted aliases, ie.
+// "/main.js" to "/main.3f2a1c9d.js".
+type Manifest map[string]string
+
+type FingerprintedDir struct {
+	http.File
+	fs      *FingerprintedFileSystem
+	dirPath string
+	entries []os.FileInfo
+	loaded  bool
+	offset  int
+}
+
+// Exposes the fingerprinted aliases of the files of the underlying filesystem
+// next to the originals, and the manifest as a JSON file at the root.
+type FingerprintedFileSystem struct {
+	fs           http.FileSystem
+	rules        []filterRule
+	manifestName string
+	manifest     Manifest
+	modTime      time.Time
+	aliases      map[string]string
+	mutex        *sync.Mutex
+}
+
+// Get the fingerprinted alias of the file or the logical name itself if the
+// file is not fingerprinted.
+func (m Manifest) Resolve(name string) string {
+	if alias, ok := m[name]; ok {
+		return alias
+	}
+
+	if !strings.HasPrefix(name, "/") {
+		if alias, ok := m["/"+name]; ok {
+			return alias[1:]
+		}
+	}
+	return name
+}
+
+// Template functions resolving the logical names, ie.
+// <script src="{{ asset "/main.js" }}"></script>. Use
+// html/template.FuncMap(m.TemplateFuncs()) with html/template.
+func (m Manifest) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"asset": m.Resolve,
+	}
+}
+
+// Read a manifest stored in a filesystem, ie. in the assets embedded by gen.
+func LoadManifest(fs http.FileSystem, name string) (Manifest, error) {
+	file, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(file).Decode(&manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func fingerprintedName(name string, data []byte) string {
+	sum := sha256.Sum256(data)
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:])[:8] + ext
+}
+
+func (fs *FingerprintedFileSystem) shouldFingerprint(components []string) bool {
+	if len(fs.rules) == 0 {
+		return true
+	}
+
+	for _, rule := range fs.rules {
+		if rule.matches(components) {
+			return true
+		}
+	}
+	return false
+}
+
+func (fs *FingerprintedFileSystem) readFile(name string) ([]byte, error) {
+	file, err := fs.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+func (m Manifest) equal(other Manifest) bool {
+	if len(m) != len(other) {
+		return false
+	}
+	for name, alias := range m {
+		if otherAlias, ok := other[name]; !ok || otherAlias != alias {
+			return false
+		}
+	}
+	return true
+}
+
+// Recompute the fingerprints, ie. after the files have changed in development.
+// The manifest is as recent as the most recently modified fingerprinted file,
+// or the refresh, if it has changed otherwise, ie. by the removal of a file.
+func (fs *FingerprintedFileSystem) Refresh() error {
+	manifest := make(Manifest)
+	var modTime time.Time
+	err := Walk(fs.fs, "/", func(name string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !fs.shouldFingerprint(splitPath(name)) {
+			return err
+		}
+
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+
+		data, err := fs.readFile(name)
+		if err != nil {
+			return err
+		}
+		manifest[name] = fingerprintedName(name, data)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	aliases := make(map[string]string)
+	for name, alias := range manifest {
+		aliases[alias] = name
+	}
+
+	fs.mutex.Lock()
+	if fs.manifest == nil || !fs.manifest.equal(manifest) {
+		if fs.manifest != nil && !modTime.After(fs.modTime) {
+			modTime = time.Now()
+		}
+		fs.modTime = modTime
+	}
+	fs.manifest = manifest
+	fs.aliases = aliases
+	fs.mutex.Unlock()
+	return nil
+}
+
+func (fs *FingerprintedFileSystem) Manifest() Manifest {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	return fs.manifest
+}
+
+func (fs *FingerprintedFileSystem) manifestFile() (http.File, error) {
+	fs.mutex.Lock()
+	manifest, modTime := fs.manifest, fs.modTime
+	fs.mutex.Unlock()
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := NewMemoryFileSystem(map[string]MemoryFile{
+		fs.manifestName: {Data: data, ModTime: modTime},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files.Open(fs.manifestName)
+}
+
+func (d *FingerprintedDir) load() error {
+	if d.loaded {
+		return nil
+	}
+
+	entries, err := d.File.Readdir(-1)
+	if err != nil {
+		return err
+	}
+
+	manifest := d.fs.Manifest()
+	for _, entry := range entries {
+		d.entries = append(d.entries, entry)
+		alias, ok := manifest[path.Join(d.dirPath, entry.Name())]
+		if ok {
+			d.entries = append(d.entries, renamedInfo{entry, path.Base(alias)})
+		}
+	}
+
+	if d.fs.manifestName != "" && path.Dir(d.fs.manifestName) == d.dirPath {
+		file, err := d.fs.manifestFile()
+		if err != nil {
+			return err
+		}
+		info, err := file.Stat()
+		file.Close()
+		if err != nil {
+			return err
+		}
+		d.entries = append(d.entries, info)
+	}
+
+	sortEntries(d.entries)
+	d.loaded = true
+	return nil
+}
+
+func (d *FingerprintedDir) Readdir(count int) ([]os.FileInfo, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+	return readdirPage(d.entries, &d.offset, count)
+}
+
+func (fs *FingerprintedFileSystem) Open(name string) (http.File, error) {
+	if !strings.HasPrefix(name, "/") {
+		return fs.fs.Open(name)
+	}
+	name = path.Clean(name)
+
+	if fs.manifestName != "" && name == fs.manifestName {
+		return fs.manifestFile()
+	}
+
+	fs.mutex.Lock()
+	logical, ok := fs.aliases[name]
+	fs.mutex.Unlock()
+	if ok {
+		file, err := fs.fs.Open(logical)
+		if err != nil {
+			return nil, err
+		}
+		return renamedFile{file, path.Base(name)}, nil
+	}
+
+	file, err := fs.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if stat.IsDir() {
+		return &FingerprintedDir{File: file, fs: fs, dirPath: name}, nil
+	}
+	return file, nil
+}
+
+// Build a new fingerprinting filesystem. Only the files matching the patterns,
+// ie. "**/*.js", get the aliases; all of them do if there are no patterns. The
+// manifest is exposed under manifestName, ie. "/manifest.json", unless it is
+// empty.
+func NewFingerprintedFileSystem(fs http.FileSystem, patterns []string,
+	manifestName string) (*FingerprintedFileSystem, error) {
+
+	if manifestName != "" {
+		if !strings.HasPrefix(manifestName, "/") {
+			return nil, errors.New("the manifest name must start with a leading slash")
+		}
+		manifestName = path.Clean(manifestName)
+	}
+
+	fpFs := &FingerprintedFileSystem{
+		fs:           fs,
+		manifestName: manifestName,
+		mutex:        &sync.Mutex{},
+	}
+
+	rules, err := parseRules(patterns)
+	if err != nil {
+		return nil, err
+	}
+	fpFs.rules = rules
+
+	if err := fpFs.Refresh(); err != nil {
+		return nil, err
+	}
+	return fpFs, nil
+}
@@ -10,10 +10,14 @@ package fs
 import (
 	"bytes"
 	"fmt"
+	"io"
+	iofs "io/fs"
 	"os"
 	"time"
 )
 
+// A file or a directory that does not exist on disk. The size of a file
+// defaults to the one of its data; the directories are empty.
 type VirtualFile struct {
 	FileName  string
 	FileSize  int64
@@ -22,10 +26,43 @@ type VirtualFile struct {
 }
 
 func (f VirtualFile) Readdir(count int) ([]os.FileInfo, error) {
-	if f.FileIsDir {
-		return []os.FileInfo{}, nil
+	if !f.FileIsDir {
+		return nil, fmt.Errorf("cannot Readdir from a file")
+	}
+
+	if count > 0 {
+		return nil, io.EOF
+	}
+	return []os.FileInfo{}, nil
+}
+
+func (f VirtualFile) ReadDir(count int) ([]iofs.DirEntry, error) {
+	if !f.FileIsDir {
+		return nil, fmt.Errorf("cannot ReadDir from a file")
+	}
+
+	if count > 0 {
+		return nil, io.EOF
 	}
-	return nil, fmt.Errorf("cannot Readdir from a file")
+	return []iofs.DirEntry{}, nil
+}
+
+func (f VirtualFile) Read(p []byte) (int, error) {
+	if f.Reader == nil {
+		return 0, io.EOF
+	}
+	return f.Reader.Read(p)
+}
+
+// Seeking to the beginning of a directory restarts its (empty) listing.
+func (f VirtualFile) Seek(offset int64, whence int) (int64, error) {
+	if f.Reader == nil {
+		if offset != 0 || whence != io.SeekStart {
+			return 0, fmt.Errorf("cannot Seek within an empty file")
+		}
+		return 0, nil
+	}
+	return f.Reader.Seek(offset, whence)
 }
 
 func (f VirtualFile) Stat() (os.FileInfo, error) {
@@ -36,18 +73,23 @@ func (f VirtualFile) IsDir() bool {
 	return f.FileIsDir
 }
 
+// The file has no modification time, so it reports the zero one, which
+// http.ServeContent does not advertise.
 func (f VirtualFile) ModTime() time.Time {
-	return time.Now()
+	return time.Time{}
 }
 
 func (f VirtualFile) Mode() os.FileMode {
 	if f.FileIsDir {
-		return 0555
+		return os.ModeDir | 0555
 	}
 	return 0444
 }
 
 func (f VirtualFile) Size() int64 {
+	if f.FileSize == 0 && f.Reader != nil {
+		return f.Reader.Size()
+	}
 	return f.FileSize
 }
 
@@ -0,0 +1,228 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// A single line of an ignore file.
+type ignoreRule struct {
+	base     []string
+	glob     []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+type IgnoreDir struct {
+	http.File
+	fs      IgnoreFileSystem
+	dirPath string
+}
+
+type IgnoreFileSystem struct {
+	fs         http.FileSystem
+	ignoreFile string
+	rootRules  []ignoreRule
+	rules      map[string][]ignoreRule
+	mutex      *sync.Mutex
+}
+
+func (r ignoreRule) matches(components []string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+
+	if len(components) <= len(r.base) {
+		return false
+	}
+	for i, name := range r.base {
+		if components[i] != name {
+			return false
+		}
+	}
+	return matchGlob(r.glob, components[len(r.base):])
+}
+
+// Parse the lines of an ignore file located in the given directory following
+// the semantics of gitignore.
+func parseIgnoreRules(base []string, lines []string) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+
+		// Trailing spaces are ignored unless escaped
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+			line = line[:len(line)-1]
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+
+		// A slash at the beginning or in the middle anchors the pattern at the
+		// directory of the ignore file
+		rule.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+
+		line = strings.Replace(line, "[!", "[^", -1)
+		rule.glob = strings.Split(line, "/")
+
+		// A trailing "/**" matches everything inside, but not the directory
+		// itself
+		if n := len(rule.glob); n > 1 && rule.glob[n-1] == "**" {
+			rule.glob = append(rule.glob[:n-1], "*", "**")
+		}
+		if !rule.anchored {
+			rule.glob = append([]string{"**"}, rule.glob...)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// Get the rules of the ignore file placed in the given directory.
+func (fs IgnoreFileSystem) dirRules(components []string) ([]ignoreRule, error) {
+	if fs.ignoreFile == "" {
+		return nil, nil
+	}
+
+	dirPath := "/" + strings.Join(components, "/")
+
+	fs.mutex.Lock()
+	rules, ok := fs.rules[dirPath]
+	fs.mutex.Unlock()
+	if ok {
+		return rules, nil
+	}
+
+	filePath := strings.TrimSuffix(dirPath, "/") + "/" + fs.ignoreFile
+	file, err := fs.fs.Open(filePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if err == nil {
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		base := append([]string{}, components...)
+		rules = parseIgnoreRules(base, strings.Split(string(data), "\n"))
+	}
+
+	fs.mutex.Lock()
+	fs.rules[dirPath] = rules
+	fs.mutex.Unlock()
+	return rules, nil
+}
+
+// Check whether the path is ignored. Like git, we do not look inside of the
+// ignored directories, so the files within them cannot be re-included.
+func (fs IgnoreFileSystem) ignored(components []string, isDir bool) (bool, error) {
+	rules := fs.rootRules
+	for i := 1; i <= len(components); i++ {
+		moreRules, err := fs.dirRules(components[:i-1])
+		if err != nil {
+			return false, err
+		}
+		rules = append(rules[:len(rules):len(rules)], moreRules...)
+
+		entryIsDir := isDir || i < len(components)
+		ignored := false
+		for _, rule := range rules {
+			if rule.matches(components[:i], entryIsDir) {
+				ignored = !rule.negate
+			}
+		}
+
+		if ignored {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (d IgnoreDir) Readdir(count int) ([]os.FileInfo, error) {
+	return filterDir(d.File, d.dirPath, count, func(components []string, info os.FileInfo) (bool, error) {
+		ignored, err := d.fs.ignored(components, info.IsDir())
+		return !ignored, err
+	})
+}
+
+func (fs IgnoreFileSystem) Open(path string) (http.File, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, os.ErrNotExist
+	}
+
+	file, err := fs.fs.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	components := splitPath(path)
+	ignored, err := fs.ignored(components, stat.IsDir())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if ignored {
+		file.Close()
+		return nil, os.ErrNotExist
+	}
+
+	if stat.IsDir() {
+		return IgnoreDir{file, fs, "/" + strings.Join(components, "/")}, nil
+	}
+	return file, nil
+}
+
+// Build a new filesystem hiding the paths matched by the gitignore-style rules.
+// The rules passed in explicitly apply at the root; if ignoreFile is not empty,
+// the files of this name, ie. ".gitignore" or ".npmignore", are read from
+// every directory and their rules apply to the directory they are in and
+// below. The ignore files themselves are served unless they are ignored too.
+func NewIgnoreFileSystem(fs http.FileSystem, ignoreFile string, rules []string) http.FileSystem {
+	return IgnoreFileSystem{
+		fs:         fs,
+		ignoreFile: ignoreFile,
+		rootRules:  parseIgnoreRules([]string{}, rules),
+		rules:      make(map[string][]ignoreRule),
+		mutex:      &sync.Mutex{},
+	}
+}
@@ -8,17 +8,112 @@
 package fs
 
 import (
+	"errors"
 	"net/http"
+	"os"
+	"path"
+	"strings"
 )
 
+// A filesystem for single page apps: the routes that do not exist fall back to
+// an index file. Only the paths without extensions fall back, so that broken
+// references to assets result in real 404s. The directories without index
+// files do not exist either, so that they are never listed. The errors other
+// than the non-existence of the file are propagated as they are.
+//
+// When used as a http.Handler, the requests accepting text/html fall back even
+// if their paths have extensions, ie. "/users/john.doe", or are directories.
 type Index404Fs struct {
 	Fs http.FileSystem
+
+	// Map of path prefixes to the fallback files, ie. "/admin/" to
+	// "/admin/index.html"; the longest matching prefix wins. The paths not
+	// matching any prefix fall back to "/index.html".
+	Fallbacks map[string]string
+}
+
+func (fs *Index404Fs) fallbackFor(name string) string {
+	fallback := "/index.html"
+	longest := -1
+	for prefix, file := range fs.Fallbacks {
+		if strings.HasPrefix(name, prefix) && len(prefix) > longest {
+			fallback = file
+			longest = len(prefix)
+		}
+	}
+	return fallback
 }
 
 func (fs *Index404Fs) Open(name string) (http.File, error) {
 	file, err := fs.Fs.Open(name)
+	if err == nil {
+		return fs.hideUnindexedDir(name, file)
+	}
+
+	if !errors.Is(err, os.ErrNotExist) || path.Ext(name) != "" {
+		return nil, err
+	}
+	return fs.Fs.Open(fs.fallbackFor(name))
+}
+
+// Report the directory without an index file as non-existent, since the file
+// server would list it otherwise.
+func (fs *Index404Fs) hideUnindexedDir(name string, file http.File) (http.File, error) {
+	stat, err := file.Stat()
 	if err != nil {
-		return fs.Fs.Open("/index.html")
+		file.Close()
+		return nil, err
+	}
+
+	if !stat.IsDir() {
+		return file, nil
+	}
+
+	index, err := fs.Fs.Open(path.Join(name, "index.html"))
+	if err == nil {
+		index.Close()
+		return file, nil
+	}
+
+	file.Close()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return nil, err
+}
+
+func acceptsHTML(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		if strings.Contains(accept, "text/html") {
+			return true
+		}
+	}
+	return false
+}
+
+func (fs *Index404Fs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean("/" + r.URL.Path)
+	if acceptsHTML(r) {
+		file, err := fs.Open(name)
+		if err == nil {
+			file.Close()
+		} else if errors.Is(err, os.ErrNotExist) {
+			fallback := fs.fallbackFor(name)
+			file, err := fs.Fs.Open(fallback)
+			if err != nil {
+				http.NotFound(w, r)
+				return
+			}
+			defer file.Close()
+
+			stat, err := file.Stat()
+			if err != nil {
+				http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			http.ServeContent(w, r, fallback, stat.ModTime(), file)
+			return
+		}
 	}
-	return file, err
+	http.FileServer(fs).ServeHTTP(w, r)
 }
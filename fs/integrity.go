@@ -0,0 +1,185 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Maps the names of the files to their SHA-256 digests in the Subresource
+// Integrity format, ie. "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=".
+type IntegrityManifest map[string]string
+
+// The differences between a filesystem and its integrity manifest.
+type IntegrityError struct {
+	Modified   []string
+	Missing    []string
+	Unexpected []string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("integrity check failed: %d modified, %d missing, %d unexpected files",
+		len(e.Modified), len(e.Missing), len(e.Unexpected))
+}
+
+// Get the digest of the file for the integrity attribute; empty if the file
+// is not in the manifest.
+func (m IntegrityManifest) Integrity(name string) string {
+	if !strings.HasPrefix(name, "/") {
+		name = "/" + name
+	}
+	return m[path.Clean(name)]
+}
+
+// Template functions providing the digests, ie.
+// <script src="/main.js" integrity="{{ integrity "/main.js" }}"></script>.
+// The map is a text/template one; html/template takes it after a conversion.
+func (m IntegrityManifest) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"integrity": m.Integrity,
+	}
+}
+
+func (m IntegrityManifest) compare(actual IntegrityManifest) error {
+	var result IntegrityError
+	for name, digest := range m {
+		actualDigest, ok := actual[name]
+		switch {
+		case !ok:
+			result.Missing = append(result.Missing, name)
+		case actualDigest != digest:
+			result.Modified = append(result.Modified, name)
+		}
+	}
+
+	for name := range actual {
+		if _, ok := m[name]; !ok {
+			result.Unexpected = append(result.Unexpected, name)
+		}
+	}
+
+	if len(result.Modified) == 0 && len(result.Missing) == 0 && len(result.Unexpected) == 0 {
+		return nil
+	}
+
+	sort.Strings(result.Modified)
+	sort.Strings(result.Missing)
+	sort.Strings(result.Unexpected)
+	return &result
+}
+
+// Check that the filesystem holds exactly the files of the manifest, with the
+// same contents. The differences are reported as an *IntegrityError.
+func (m IntegrityManifest) Verify(fs http.FileSystem) error {
+	actual, err := ComputeIntegrity(fs)
+	if err != nil {
+		return err
+	}
+	return m.compare(actual)
+}
+
+// Compute the digests of all the files of the filesystem.
+func ComputeIntegrity(fs http.FileSystem) (IntegrityManifest, error) {
+	manifest := make(IntegrityManifest)
+	err := Walk(fs, "/", func(name string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		file, err := fs.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		hash := sha256.New()
+		if _, err := io.Copy(hash, file); err != nil {
+			return err
+		}
+		manifest[name] = "sha256-" + base64.StdEncoding.EncodeToString(hash.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Read an integrity manifest stored in a filesystem, ie. in the assets
+// embedded by gen.
+func LoadIntegrityManifest(fs http.FileSystem, name string) (IntegrityManifest, error) {
+	file, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var manifest IntegrityManifest
+	if err := json.NewDecoder(file).Decode(&manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Load the integrity manifest stored in the filesystem and check the rest of
+// the filesystem against it, ie. at startup. The manifest is returned even if
+// the check fails.
+func VerifyIntegrity(fs http.FileSystem, manifestName string) (IntegrityManifest, error) {
+	manifestName = path.Clean("/" + manifestName)
+	manifest, err := LoadIntegrityManifest(fs, manifestName)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, err := ComputeIntegrity(fs)
+	if err != nil {
+		return manifest, err
+	}
+	delete(actual, manifestName)
+	return manifest, manifest.compare(actual)
+}
+
+// Build a new filesystem exposing the integrity manifest of the given one as
+// a JSON file under manifestName, ie. "/integrity.json". The digests are
+// computed once, when the filesystem is built.
+func NewIntegrityFileSystem(fs http.FileSystem, manifestName string) (http.FileSystem, error) {
+	if !strings.HasPrefix(manifestName, "/") {
+		return nil, errors.New("the manifest name must start with a leading slash")
+	}
+	manifestName = path.Clean(manifestName)
+
+	manifest, err := ComputeIntegrity(fs)
+	if err != nil {
+		return nil, err
+	}
+	delete(manifest, manifestName)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	manifestFs, err := NewMemoryFileSystem(map[string]MemoryFile{
+		manifestName: {Data: data},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewUnionFileSystem(manifestFs, fs), nil
+}
@@ -0,0 +1,181 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"io"
+	iofs "io/fs"
+	"net/http"
+	"path"
+	"sort"
+)
+
+type ComposedFSEntry struct {
+	MountPoint string
+	FS         iofs.FS
+}
+
+// An io/fs view of a http.FileSystem. It implements ReadDirFS, ReadFileFS,
+// StatFS and SubFS.
+type HTTPFS struct {
+	fs http.FileSystem
+}
+
+// A file of HTTPFS; the directories implement ReadDirFile.
+type HTTPFSFile struct {
+	http.File
+}
+
+// A http.FileSystem rooted at a subdirectory of another one.
+type subFileSystem struct {
+	fs  http.FileSystem
+	dir string
+}
+
+func (f HTTPFSFile) ReadDir(count int) ([]iofs.DirEntry, error) {
+	infos, err := f.File.Readdir(count)
+	entries := make([]iofs.DirEntry, 0, len(infos))
+	for _, info := range infos {
+		entries = append(entries, iofs.FileInfoToDirEntry(info))
+	}
+	return entries, err
+}
+
+func (fs subFileSystem) Open(name string) (http.File, error) {
+	return fs.fs.Open(path.Join(fs.dir, name))
+}
+
+func (fs HTTPFS) open(op, name string) (http.File, error) {
+	if !iofs.ValidPath(name) {
+		return nil, &iofs.PathError{Op: op, Path: name, Err: iofs.ErrInvalid}
+	}
+
+	file, err := fs.fs.Open(path.Join("/", name))
+	if err != nil {
+		return nil, &iofs.PathError{Op: op, Path: name, Err: err}
+	}
+	return file, nil
+}
+
+func (fs HTTPFS) Open(name string) (iofs.File, error) {
+	file, err := fs.open("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return HTTPFSFile{file}, nil
+}
+
+func (fs HTTPFS) ReadDir(name string) ([]iofs.DirEntry, error) {
+	file, err := fs.open("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries, err := HTTPFSFile{file}.ReadDir(-1)
+	if err != nil {
+		return nil, &iofs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+func (fs HTTPFS) ReadFile(name string) ([]byte, error) {
+	file, err := fs.open("read", name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, &iofs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+func (fs HTTPFS) Stat(name string) (iofs.FileInfo, error) {
+	file, err := fs.open("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, &iofs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return info, nil
+}
+
+func (fs HTTPFS) Sub(dir string) (iofs.FS, error) {
+	if !iofs.ValidPath(dir) {
+		return nil, &iofs.PathError{Op: "sub", Path: dir, Err: iofs.ErrInvalid}
+	}
+
+	if dir == "." {
+		return fs, nil
+	}
+	return HTTPFS{subFileSystem{fs.fs, path.Join("/", dir)}}, nil
+}
+
+// Expose a http.FileSystem as an io/fs filesystem, so that it can be used with
+// fs.WalkDir, template.ParseFS and the like.
+func ToFS(fs http.FileSystem) iofs.FS {
+	return HTTPFS{fs}
+}
+
+// Expose an io/fs filesystem, ie. embed.FS, as a http.FileSystem, so that it
+// can be composed and filtered by the rest of this package.
+func FromFS(fsys iofs.FS) http.FileSystem {
+	return http.FS(fsys)
+}
+
+// The io/fs counterpart of NewComposedFileSystem.
+func NewComposedFS(root iofs.FS, entries []ComposedFSEntry) iofs.FS {
+	httpEntries := make([]ComposedEntry, 0, len(entries))
+	for _, entry := range entries {
+		httpEntries = append(httpEntries, ComposedEntry{entry.MountPoint, FromFS(entry.FS)})
+	}
+	return ToFS(NewComposedFileSystem(FromFS(root), httpEntries))
+}
+
+// The io/fs counterpart of NewUnionFileSystem.
+func NewUnionFS(layers ...iofs.FS) iofs.FS {
+	httpLayers := make([]http.FileSystem, 0, len(layers))
+	for _, layer := range layers {
+		httpLayers = append(httpLayers, FromFS(layer))
+	}
+	return ToFS(NewUnionFileSystem(httpLayers...))
+}
+
+// The io/fs counterpart of NewFilteredFileSystem.
+func NewFilteredFS(fsys iofs.FS, whitelist []string) iofs.FS {
+	return ToFS(NewFilteredFileSystem(FromFS(fsys), whitelist))
+}
+
+// The io/fs counterpart of NewBlacklistedFileSystem.
+func NewBlacklistedFS(fsys iofs.FS, blacklist []string) (iofs.FS, error) {
+	fs, err := NewBlacklistedFileSystem(FromFS(fsys), blacklist)
+	if err != nil {
+		return nil, err
+	}
+	return ToFS(fs), nil
+}
+
+// The io/fs counterpart of NewIgnoreFileSystem.
+func NewIgnoreFS(fsys iofs.FS, ignoreFile string, rules []string) iofs.FS {
+	return ToFS(NewIgnoreFileSystem(FromFS(fsys), ignoreFile, rules))
+}
+
+// The io/fs counterpart of Index404Fs.
+func NewIndex404FS(fsys iofs.FS) iofs.FS {
+	return ToFS(&Index404Fs{Fs: FromFS(fsys)})
+}
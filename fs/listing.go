@@ -0,0 +1,296 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const listingTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Index of {{ .Path }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.3em 1em; border-bottom: 1px solid #eee; }
+th a { color: inherit; }
+td.size, th.size { text-align: right; }
+a { color: #0645ad; text-decoration: none; }
+a:hover { text-decoration: underline; }
+.pages { margin-top: 1em; }
+.pages a, .pages span { margin-right: 0.5em; }
+</style>
+</head>
+<body>
+<h1>Index of {{ .Path }}</h1>
+<table>
+<tr>
+<th><a href="?sort=name&amp;order={{ .NextOrder }}">Name</a></th>
+<th class="size"><a href="?sort=size&amp;order={{ .NextOrder }}">Size</a></th>
+<th><a href="?sort=time&amp;order={{ .NextOrder }}">Modified</a></th>
+</tr>
+{{- if ne .Path "/" }}
+<tr><td><a href="../">../</a></td><td></td><td></td></tr>
+{{- end }}
+{{- range .Entries }}
+<tr>
+<td><a href="{{ .Href }}">{{ .Name }}{{ if .IsDir }}/{{ end }}</a></td>
+<td class="size">{{ if not .IsDir }}{{ .Size }}{{ end }}</td>
+<td>{{ if not .ModTime.IsZero }}{{ .ModTime.Format "2006-01-02 15:04:05" }}{{ end }}</td>
+</tr>
+{{- end }}
+</table>
+{{- if gt .Pages 1 }}
+<div class="pages">
+{{- range .PageLinks }}
+{{- if .Current }}<span>{{ .Number }}</span>{{ else }}<a href="{{ .Href }}">{{ .Number }}</a>{{ end }}
+{{- end }}
+</div>
+{{- end }}
+</body>
+</html>
+`
+
+type ListingOptions struct {
+	// Number of entries per page; 100 if zero
+	PerPage int
+
+	// Serve the index.html files of directories instead of listing them
+	ServeIndex bool
+}
+
+type ListingEntry struct {
+	Name    string    `json:"name"`
+	Href    string    `json:"href"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Mode    string    `json:"mode"`
+	IsDir   bool      `json:"isDir"`
+}
+
+type Listing struct {
+	Path    string         `json:"path"`
+	Entries []ListingEntry `json:"entries"`
+	Total   int            `json:"total"`
+	Page    int            `json:"page"`
+	PerPage int            `json:"perPage"`
+	Pages   int            `json:"pages"`
+	Sort    string         `json:"sort"`
+	Order   string         `json:"order"`
+}
+
+type pageLink struct {
+	Number  int
+	Href    string
+	Current bool
+}
+
+// Lists directories as HTML pages or, if requested with "?format=json" or
+// "Accept: application/json", as JSON documents. The listings may be sorted by
+// "name", "size", or "time" with the "sort" and "order" query parameters, and
+// paginated with "page" and "perPage". The files are served as they are.
+type ListingHandler struct {
+	fs         http.FileSystem
+	fileServer http.Handler
+	opts       ListingOptions
+	tmpl       *template.Template
+}
+
+func (l Listing) NextOrder() string {
+	if l.Order == "asc" {
+		return "desc"
+	}
+	return "asc"
+}
+
+func (l Listing) PageLinks() []pageLink {
+	var links []pageLink
+	for i := 1; i <= l.Pages; i++ {
+		query := url.Values{}
+		query.Set("sort", l.Sort)
+		query.Set("order", l.Order)
+		query.Set("page", strconv.Itoa(i))
+		query.Set("perPage", strconv.Itoa(l.PerPage))
+		links = append(links, pageLink{i, "?" + query.Encode(), i == l.Page})
+	}
+	return links
+}
+
+func sortListing(entries []ListingEntry, key, order string) {
+	less := func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		switch key {
+		case "size":
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+		case "time":
+			if !a.ModTime.Equal(b.ModTime) {
+				return a.ModTime.Before(b.ModTime)
+			}
+		}
+		return a.Name < b.Name
+	}
+
+	if order == "desc" {
+		sort.SliceStable(entries, func(i, j int) bool { return less(j, i) })
+		return
+	}
+	sort.SliceStable(entries, less)
+}
+
+func wantsJSON(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "json"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+func queryInt(query url.Values, name string, def int) int {
+	val, err := strconv.Atoi(query.Get(name))
+	if err != nil || val < 1 {
+		return def
+	}
+	return val
+}
+
+func (h ListingHandler) listing(r *http.Request, name string, dir http.File) (Listing, error) {
+	infos, err := dir.Readdir(-1)
+	if err != nil {
+		return Listing{}, err
+	}
+
+	query := r.URL.Query()
+	listing := Listing{
+		Path:    name,
+		Total:   len(infos),
+		Page:    queryInt(query, "page", 1),
+		PerPage: queryInt(query, "perPage", h.opts.PerPage),
+		Sort:    query.Get("sort"),
+		Order:   query.Get("order"),
+	}
+
+	if listing.Sort != "size" && listing.Sort != "time" {
+		listing.Sort = "name"
+	}
+	if listing.Order != "desc" {
+		listing.Order = "asc"
+	}
+
+	entries := make([]ListingEntry, 0, len(infos))
+	for _, info := range infos {
+		href := url.PathEscape(info.Name())
+		if info.IsDir() {
+			href += "/"
+		}
+		entries = append(entries, ListingEntry{
+			Name:    info.Name(),
+			Href:    href,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Mode:    info.Mode().String(),
+			IsDir:   info.IsDir(),
+		})
+	}
+	sortListing(entries, listing.Sort, listing.Order)
+
+	listing.Pages = (len(entries) + listing.PerPage - 1) / listing.PerPage
+	start := (listing.Page - 1) * listing.PerPage
+	if start > len(entries) {
+		start = len(entries)
+	}
+	end := start + listing.PerPage
+	if end > len(entries) {
+		end = len(entries)
+	}
+	listing.Entries = entries[start:end]
+	return listing, nil
+}
+
+func (h ListingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	upath := r.URL.Path
+	if !strings.HasPrefix(upath, "/") {
+		upath = "/" + upath
+	}
+	name := path.Clean(upath)
+
+	file, err := h.fs.Open(name)
+	if err != nil {
+		h.fileServer.ServeHTTP(w, r)
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil || !stat.IsDir() {
+		h.fileServer.ServeHTTP(w, r)
+		return
+	}
+
+	if !strings.HasSuffix(upath, "/") {
+		target := path.Base(name) + "/"
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+
+	if h.opts.ServeIndex {
+		if index, err := h.fs.Open(path.Join(name, "index.html")); err == nil {
+			index.Close()
+			h.fileServer.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	if name != "/" {
+		name += "/"
+	}
+
+	listing, err := h.listing(r, name, file)
+	if err != nil {
+		http.Error(w, "Error reading directory", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+	if wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listing)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	h.tmpl.Execute(w, listing)
+}
+
+// Build a new listing handler. Since the listings come from the filesystem,
+// the filtered filesystems only list what they serve.
+func NewListingHandler(fs http.FileSystem, opts ListingOptions) http.Handler {
+	if opts.PerPage <= 0 {
+		opts.PerPage = 100
+	}
+
+	return ListingHandler{
+		fs:         fs,
+		fileServer: http.FileServer(fs),
+		opts:       opts,
+		tmpl:       template.Must(template.New("listing").Parse(listingTemplate)),
+	}
+}
@@ -0,0 +1,191 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const liveReloadScript = `<script>(function() {
+  var source = new EventSource(%q);
+  source.addEventListener("reload", function() { window.location.reload(); });
+})();</script>
+`
+
+// Serves the wrapped handler injecting a reload script into the HTML pages,
+// and pushes the reload notices to the browsers over server-sent events when
+// the watched filesystem changes.
+type LiveReloadHandler struct {
+	fs             *WatchedFileSystem
+	endpoint       string
+	wrappedHandler http.Handler
+}
+
+// Buffers the successful HTML responses, so that the script can be injected
+// into them. Everything else, ie. the event streams or the websockets proxied
+// to a dev server, passes straight through.
+type injectingWriter struct {
+	http.ResponseWriter
+	status    int
+	buffering bool
+	body      bytes.Buffer
+}
+
+func (w *injectingWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+
+	w.status = status
+	ctype := w.Header().Get("Content-Type")
+	w.buffering = status == http.StatusOK && strings.HasPrefix(ctype, "text/html")
+	if !w.buffering {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *injectingWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		// Sniff the content type like net/http would, so that we know whether
+		// the response is a page
+		if _, ok := w.Header()["Content-Type"]; !ok {
+			w.Header().Set("Content-Type", http.DetectContentType(data))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.buffering {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush the response unless it is being buffered.
+func (w *injectingWriter) Flush() {
+	if w.buffering {
+		return
+	}
+
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *injectingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("the response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+func (w *injectingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (h LiveReloadHandler) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, cancel := h.fs.Subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			// Editors tend to produce bursts of changes; wait for them to settle
+			timer := time.NewTimer(100 * time.Millisecond)
+		drain:
+			for {
+				select {
+				case _, ok := <-events:
+					if !ok {
+						break drain
+					}
+				case <-timer.C:
+					break drain
+				}
+			}
+			timer.Stop()
+
+			fmt.Fprintf(w, "event: reload\ndata: %s\n\n", event.Path)
+			flusher.Flush()
+		}
+	}
+}
+
+func (h LiveReloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == h.endpoint {
+		h.serveEvents(w, r)
+		return
+	}
+
+	// We need the uncompressed body to inject the script
+	req := r.Clone(r.Context())
+	req.Header.Del("Accept-Encoding")
+
+	iw := &injectingWriter{ResponseWriter: w}
+	h.wrappedHandler.ServeHTTP(iw, req)
+	if !iw.buffering {
+		return
+	}
+
+	body := iw.body.Bytes()
+	script := []byte(fmt.Sprintf(liveReloadScript, h.endpoint))
+	if idx := bytes.LastIndex(bytes.ToLower(body), []byte("</body>")); idx != -1 {
+		body = append(body[:idx:idx], append(script, body[idx:]...)...)
+	} else {
+		body = append(body, script...)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.Header().Del("ETag")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(iw.status)
+	w.Write(body)
+}
+
+// Build a new live reload handler. The browsers subscribe to the changes at
+// the endpoint, ie. "/__livereload", which must be the path of the request as
+// seen by the handler. Meant for development builds only.
+func NewLiveReloadHandler(fs *WatchedFileSystem, endpoint string, handler http.Handler) http.Handler {
+	if handler == nil {
+		handler = http.FileServer(fs)
+	}
+	return LiveReloadHandler{fs, endpoint, handler}
+}
@@ -0,0 +1,204 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+type LocaleOptions struct {
+	// The supported locales, ie. "en", "de" or "pt-BR"
+	Locales []string
+
+	// The locale used when nothing else matches; the first supported one if
+	// empty
+	Default string
+
+	// The name of the cookie overriding the Accept-Language header, if any
+	Cookie string
+}
+
+// A filesystem serving the localized variants of the files, ie.
+// "/index.de.html" in place of "/index.html", if they exist. The locales are
+// tried in order before falling back to the file itself.
+type LocalizedFileSystem struct {
+	fs      http.FileSystem
+	locales []string
+	served  *string
+}
+
+// Serves the localized variants of the files picked according to the
+// Accept-Language header and the cookie override.
+type LocaleHandler struct {
+	fs   http.FileSystem
+	opts LocaleOptions
+	wrap func(http.FileSystem) http.Handler
+}
+
+// Sets the Content-Language header before the response goes out.
+type localeWriter struct {
+	http.ResponseWriter
+	served      *string
+	wroteHeader bool
+}
+
+// Insert the locale before the extension of the file name, ie. "index.html"
+// becomes "index.de.html".
+func localizedName(name, locale string) string {
+	ext := path.Ext(name)
+	if ext == path.Base(name) {
+		// A dotfile, ie. ".htaccess", has no extension
+		ext = ""
+	}
+	return name[:len(name)-len(ext)] + "." + locale + ext
+}
+
+func (fs LocalizedFileSystem) Open(name string) (http.File, error) {
+	name = path.Clean("/" + name)
+	if name != "/" {
+		for _, locale := range fs.locales {
+			if locale == "" {
+				continue
+			}
+
+			file, err := fs.fs.Open(localizedName(name, locale))
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+				return nil, err
+			}
+
+			stat, err := file.Stat()
+			if err != nil {
+				file.Close()
+				return nil, err
+			}
+
+			if stat.IsDir() {
+				file.Close()
+				continue
+			}
+
+			if fs.served != nil {
+				*fs.served = locale
+			}
+			return renamedFile{file, path.Base(name)}, nil
+		}
+	}
+
+	// The last file opened is the one being served
+	if fs.served != nil {
+		*fs.served = ""
+	}
+	return fs.fs.Open(name)
+}
+
+func (w *localeWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if *w.served != "" {
+			w.Header().Set("Content-Language", *w.served)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *localeWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Find the supported locale matching the tag, ie. "de" for "de-CH" or "pt-BR"
+// for "pt".
+func (h LocaleHandler) match(tag string) string {
+	for _, locale := range h.opts.Locales {
+		if strings.EqualFold(locale, tag) {
+			return locale
+		}
+	}
+
+	primary := strings.SplitN(tag, "-", 2)[0]
+	for _, locale := range h.opts.Locales {
+		if strings.EqualFold(locale, primary) {
+			return locale
+		}
+	}
+
+	for _, locale := range h.opts.Locales {
+		if strings.EqualFold(strings.SplitN(locale, "-", 2)[0], primary) {
+			return locale
+		}
+	}
+	return ""
+}
+
+// Pick the locale for the request: the cookie wins if it names a supported
+// locale, the Accept-Language header is tried next, in the order of the
+// quality values, and the default locale is the last resort.
+func (h LocaleHandler) Locale(r *http.Request) string {
+	if h.opts.Cookie != "" {
+		if cookie, err := r.Cookie(h.opts.Cookie); err == nil {
+			if locale := h.match(cookie.Value); locale != "" {
+				return locale
+			}
+		}
+	}
+
+	for _, tag := range parseQualityList(r.Header.Values("Accept-Language")...) {
+		if tag.value == "*" || tag.q <= 0 {
+			continue
+		}
+		if locale := h.match(tag.value); locale != "" {
+			return locale
+		}
+	}
+	return h.opts.Default
+}
+
+func (h LocaleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept-Language")
+	if h.opts.Cookie != "" {
+		w.Header().Add("Vary", "Cookie")
+	}
+
+	locales := []string{h.Locale(r)}
+	if locales[0] != h.opts.Default {
+		locales = append(locales, h.opts.Default)
+	}
+
+	served := ""
+	fs := LocalizedFileSystem{h.fs, locales, &served}
+	h.wrap(fs).ServeHTTP(&localeWriter{ResponseWriter: w, served: &served}, r)
+}
+
+// Build a new filesystem serving the variants localized for the given
+// locales.
+func NewLocalizedFileSystem(fs http.FileSystem, locales ...string) http.FileSystem {
+	return LocalizedFileSystem{fs, locales, nil}
+}
+
+// Build a new locale-negotiating handler. The wrap function builds the handler
+// serving the filesystem localized for each request, ie. an Index404Fs for the
+// single page apps; a plain file server if nil.
+func NewLocaleHandler(fs http.FileSystem, opts LocaleOptions, wrap func(http.FileSystem) http.Handler) http.Handler {
+	if opts.Default == "" && len(opts.Locales) != 0 {
+		opts.Default = opts.Locales[0]
+	}
+
+	if wrap == nil {
+		wrap = http.FileServer
+	}
+	return LocaleHandler{fs, opts, wrap}
+}
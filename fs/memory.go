@@ -0,0 +1,245 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+type MemoryFile struct {
+	Data    []byte
+	ModTime time.Time
+	Mode    os.FileMode
+}
+
+type memoryNode struct {
+	name     string
+	size     int64
+	modTime  time.Time
+	mode     os.FileMode
+	children map[string]*memoryNode
+	open     func() io.ReadSeeker
+}
+
+// FileInfo of a node of the in-memory filesystem.
+type memoryInfo struct {
+	node *memoryNode
+}
+
+// An open file of the in-memory filesystem.
+type MemoryHandle struct {
+	io.ReadSeeker
+	node *memoryNode
+}
+
+// An open directory listing its entries in pages, like os.File.Readdir does.
+// The entries are loaded by the first Readdir unless they are given upfront;
+// seeking to the beginning of the directory restarts the listing.
+type dirHandle struct {
+	info    os.FileInfo
+	load    func() ([]os.FileInfo, error)
+	entries []os.FileInfo
+	offset  int
+}
+
+// An in-memory filesystem built from a map of paths to the file contents. The
+// directories are synthesized from the paths of the files.
+type MemoryFileSystem struct {
+	root *memoryNode
+}
+
+func (i memoryInfo) Name() string       { return i.node.name }
+func (i memoryInfo) Size() int64        { return i.node.size }
+func (i memoryInfo) Mode() os.FileMode  { return i.node.mode }
+func (i memoryInfo) ModTime() time.Time { return i.node.modTime }
+func (i memoryInfo) IsDir() bool        { return i.node.children != nil }
+func (i memoryInfo) Sys() interface{}   { return nil }
+
+func (h *MemoryHandle) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, fmt.Errorf("cannot Readdir from a file")
+}
+
+func (h *MemoryHandle) Stat() (os.FileInfo, error) {
+	return memoryInfo{h.node}, nil
+}
+
+func (h *MemoryHandle) Close() error {
+	if closer, ok := h.ReadSeeker.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (d *dirHandle) Readdir(count int) ([]os.FileInfo, error) {
+	if d.entries == nil && d.load != nil {
+		entries, err := d.load()
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+	}
+	return readdirPage(d.entries, &d.offset, count)
+}
+
+func (d *dirHandle) Read(p []byte) (int, error) {
+	return 0, fmt.Errorf("cannot Read from a directory")
+}
+
+func (d *dirHandle) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekStart {
+		return 0, fmt.Errorf("cannot Seek within a directory")
+	}
+	d.offset = 0
+	return 0, nil
+}
+
+func (d *dirHandle) Stat() (os.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *dirHandle) Close() error {
+	return nil
+}
+
+func (fs MemoryFileSystem) Open(name string) (http.File, error) {
+	node := fs.root
+	for _, component := range splitPath(path.Clean("/" + name)) {
+		if node.children == nil {
+			return nil, os.ErrNotExist
+		}
+
+		child, ok := node.children[component]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		node = child
+	}
+
+	if node.children != nil {
+		return &dirHandle{info: memoryInfo{node}, load: node.list}, nil
+	}
+	return &MemoryHandle{ReadSeeker: node.open(), node: node}, nil
+}
+
+// List the children of the directory sorted by name.
+func (n *memoryNode) list() ([]os.FileInfo, error) {
+	entries := make([]os.FileInfo, 0, len(n.children))
+	for _, child := range n.children {
+		entries = append(entries, memoryInfo{child})
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+// Find or create the directory below the node; the missing directories are
+// synthesized.
+func (n *memoryNode) mkdirAll(components []string) (*memoryNode, error) {
+	node := n
+	for i, component := range components {
+		child, ok := node.children[component]
+		if !ok {
+			child = newMemoryDir(component)
+			node.children[component] = child
+		}
+		if child.children == nil {
+			return nil, fmt.Errorf("%s is both a file and a directory",
+				"/"+strings.Join(components[:i+1], "/"))
+		}
+		node = child
+	}
+	return node, nil
+}
+
+// Add the file below the node, replacing the file of the same name if any.
+func (n *memoryNode) addFile(components []string, file *memoryNode) error {
+	dir, err := n.mkdirAll(components[:len(components)-1])
+	if err != nil {
+		return err
+	}
+
+	if child, ok := dir.children[file.name]; ok && child.children != nil {
+		return fmt.Errorf("%s is both a file and a directory", "/"+strings.Join(components, "/"))
+	}
+	dir.children[file.name] = file
+	return nil
+}
+
+// Move the modification time of each directory to the one of its most recently
+// modified descendant, unless the directory itself is more recent.
+func (n *memoryNode) updateModTimes() time.Time {
+	for _, child := range n.children {
+		modTime := child.modTime
+		if child.children != nil {
+			modTime = child.updateModTimes()
+		}
+		if modTime.After(n.modTime) {
+			n.modTime = modTime
+		}
+	}
+	return n.modTime
+}
+
+func newMemoryDir(name string) *memoryNode {
+	return &memoryNode{
+		name:     name,
+		mode:     os.ModeDir | 0555,
+		children: make(map[string]*memoryNode),
+	}
+}
+
+// Build a new in-memory filesystem. The paths are slash-separated; the files
+// with no mode set are read-only. The modification time of a directory is the
+// one of its most recently modified descendant.
+func NewMemoryFileSystem(files map[string]MemoryFile) (http.FileSystem, error) {
+	root := newMemoryDir("/")
+	for name, file := range files {
+		components := splitPath(path.Clean("/" + name))
+		if len(components) == 0 {
+			return nil, fmt.Errorf("invalid file name: %q", name)
+		}
+
+		dir, err := root.mkdirAll(components[:len(components)-1])
+		if err != nil {
+			return nil, err
+		}
+
+		fileName := components[len(components)-1]
+		if _, ok := dir.children[fileName]; ok {
+			return nil, fmt.Errorf("%s is both a file and a directory",
+				"/"+strings.Join(components, "/"))
+		}
+
+		mode := file.Mode.Perm()
+		if mode == 0 {
+			mode = 0444
+		}
+
+		data := file.Data
+		dir.children[fileName] = &memoryNode{
+			name:    fileName,
+			size:    int64(len(data)),
+			modTime: file.ModTime,
+			mode:    mode,
+			open:    func() io.ReadSeeker { return bytes.NewReader(data) },
+		}
+	}
+	root.updateModTimes()
+	return MemoryFileSystem{root}, nil
+}
+
+// Build a VirtualFile holding the data.
+func NewVirtualFile(name string, data []byte) VirtualFile {
+	return VirtualFile{name, int64(len(data)), false, bytes.NewReader(data)}
+}
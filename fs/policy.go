@@ -0,0 +1,225 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type SymlinkPolicy int
+
+const (
+	// Follow the symlinks wherever they point
+	SymlinksFollow SymlinkPolicy = iota
+
+	// Serve nothing reached through a symlink
+	SymlinksDeny
+
+	// Follow only the symlinks that resolve to a location within the root
+	SymlinksConfine
+)
+
+type PolicyOptions struct {
+	// Hide the files and directories whose names start with a dot, except for
+	// the ones listed in AllowedDotfiles, ie. ".well-known"
+	DenyDotfiles    bool
+	AllowedDotfiles []string
+
+	Symlinks SymlinkPolicy
+
+	// The directory on disk backing the filesystem; needed by the symlink
+	// policies unless the filesystem is a http.Dir
+	Root string
+
+	// Hide the files with these extensions, ie. ".map" or ".bak"
+	BlockedExtensions []string
+}
+
+type PolicyDir struct {
+	http.File
+	fs      PolicyFileSystem
+	dirPath string
+}
+
+// A filesystem enforcing a policy on the dotfiles, the symlinks and the file
+// extensions. Whatever the policy denies, and whatever the wrapped filesystem
+// refuses to open for lack of permissions, does not exist as far as the
+// clients are concerned, so they cannot probe for the hidden files.
+type PolicyFileSystem struct {
+	fs           http.FileSystem
+	opts         PolicyOptions
+	allowed      map[string]bool
+	blocked      map[string]bool
+	resolvedRoot string
+}
+
+// Check the policies that depend on the names alone.
+func (fs PolicyFileSystem) nameAllowed(components []string, isDir bool) bool {
+	if fs.opts.DenyDotfiles {
+		for _, component := range components {
+			if strings.HasPrefix(component, ".") && !fs.allowed[component] {
+				return false
+			}
+		}
+	}
+
+	if !isDir && len(components) != 0 {
+		ext := strings.ToLower(path.Ext(components[len(components)-1]))
+		if fs.blocked[ext] {
+			return false
+		}
+	}
+	return true
+}
+
+// Check whether any existing component of the path is a symlink.
+func hasSymlink(root string, components []string) bool {
+	current := root
+	for _, component := range components {
+		current = filepath.Join(current, component)
+		info, err := os.Lstat(current)
+		if err != nil {
+			return false
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Check the symlinks on the way from the root to the file on disk.
+func (fs PolicyFileSystem) linksAllowed(components []string) bool {
+	if fs.opts.Symlinks == SymlinksFollow || len(components) == 0 {
+		return true
+	}
+
+	if fs.opts.Symlinks == SymlinksDeny {
+		return !hasSymlink(fs.opts.Root, components)
+	}
+
+	full := filepath.Join(fs.opts.Root, filepath.FromSlash(strings.Join(components, "/")))
+	resolved, err := filepath.EvalSymlinks(full)
+	if err != nil {
+		// A dangling symlink must not reveal anything either; otherwise, let
+		// the wrapped filesystem report the missing file
+		return !hasSymlink(fs.opts.Root, components)
+	}
+
+	rel, err := filepath.Rel(fs.resolvedRoot, resolved)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func notExist(name string) error {
+	return &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+}
+
+func (d PolicyDir) Readdir(count int) ([]os.FileInfo, error) {
+	return filterDir(d.File, d.dirPath, count, func(components []string, info os.FileInfo) (bool, error) {
+		if !d.fs.nameAllowed(components, info.IsDir()) {
+			return false, nil
+		}
+
+		// The listings of the directories on disk report the symlinks
+		// themselves rather than their targets
+		if info.Mode()&os.ModeSymlink != 0 || d.fs.opts.Symlinks == SymlinksDeny {
+			return d.fs.linksAllowed(components), nil
+		}
+		return true, nil
+	})
+}
+
+func (fs PolicyFileSystem) Open(name string) (http.File, error) {
+	name = path.Clean("/" + name)
+	components := splitPath(name)
+
+	// The directory-ness is not known yet, so check the extensions later
+	if !fs.nameAllowed(components, true) || !fs.linksAllowed(components) {
+		return nil, notExist(name)
+	}
+
+	file, err := fs.fs.Open(name)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return nil, notExist(name)
+		}
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		if errors.Is(err, os.ErrPermission) {
+			return nil, notExist(name)
+		}
+		return nil, err
+	}
+
+	if !fs.nameAllowed(components, stat.IsDir()) {
+		file.Close()
+		return nil, notExist(name)
+	}
+
+	if stat.IsDir() {
+		return PolicyDir{file, fs, name}, nil
+	}
+	return file, nil
+}
+
+// Wrap the filesystem with the policy. The symlink policies need to know the
+// directory on disk backing the filesystem.
+func NewPolicyFileSystem(fs http.FileSystem, opts PolicyOptions) (http.FileSystem, error) {
+	if dir, ok := fs.(http.Dir); ok && opts.Root == "" {
+		opts.Root = string(dir)
+		if opts.Root == "" {
+			opts.Root = "."
+		}
+	}
+
+	policy := PolicyFileSystem{
+		fs:      fs,
+		opts:    opts,
+		allowed: make(map[string]bool),
+		blocked: make(map[string]bool),
+	}
+
+	for _, name := range opts.AllowedDotfiles {
+		policy.allowed[name] = true
+	}
+
+	for _, ext := range opts.BlockedExtensions {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		policy.blocked[strings.ToLower(ext)] = true
+	}
+
+	if opts.Symlinks != SymlinksFollow {
+		if opts.Root == "" {
+			return nil, fmt.Errorf("the symlink policy needs the root directory on disk")
+		}
+
+		root, err := filepath.Abs(opts.Root)
+		if err != nil {
+			return nil, err
+		}
+
+		policy.opts.Root = root
+		policy.resolvedRoot, err = filepath.EvalSymlinks(root)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve the root directory: %w", err)
+		}
+	}
+	return policy, nil
+}
@@ -0,0 +1,257 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// A rule mapping the paths matching From onto To:
+// - "/favicon.ico" to "/static/favicon.ico" aliases a single path
+// - "/app/" to "/dist/" maps a whole subtree, including "/app" itself
+// - "re:^/v[0-9.]+/(.*)$" to "/$1" maps whatever the regular expression
+// matches, expanding the submatches in To
+type RewriteRule struct {
+	From string
+	To   string
+}
+
+type rewriteRule struct {
+	from   string
+	to     string
+	re     *regexp.Regexp
+	prefix bool
+}
+
+type RewriteDir struct {
+	http.File
+	fs         RewriteFileSystem
+	dirPath    string
+	targetPath string
+	entries    []os.FileInfo
+	loaded     bool
+	offset     int
+}
+
+// A filesystem serving the paths rewritten according to the rules; the first
+// matching rule applies and the unmatched paths are served as they are. The
+// directory listings show the aliases under their own names, except for the
+// ones made by the regular expressions, which cannot be enumerated.
+type RewriteFileSystem struct {
+	fs    http.FileSystem
+	rules []rewriteRule
+}
+
+func (r rewriteRule) rewrite(name string) (string, bool) {
+	switch {
+	case r.re != nil:
+		match := r.re.FindStringSubmatchIndex(name)
+		if match == nil {
+			return "", false
+		}
+		dst := r.re.ExpandString(nil, r.to, name, match)
+		return path.Clean("/" + string(dst)), true
+	case r.prefix:
+		if name == r.from || r.from == "/" {
+			return path.Join(r.to, strings.TrimPrefix(name, r.from)), true
+		}
+		if strings.HasPrefix(name, r.from+"/") {
+			return path.Join(r.to, name[len(r.from):]), true
+		}
+		return "", false
+	default:
+		if name == r.from {
+			return r.to, true
+		}
+		return "", false
+	}
+}
+
+func (fs RewriteFileSystem) rewrite(name string) (string, bool) {
+	for _, rule := range fs.rules {
+		if target, ok := rule.rewrite(name); ok {
+			return target, true
+		}
+	}
+	return name, false
+}
+
+// Find the aliases placed directly in the given directory and the names of
+// the intermediate directories leading to the nested ones.
+func (fs RewriteFileSystem) childAliases(dir string) ([]string, []string) {
+	var aliases, dirs []string
+	seen := make(map[string]bool)
+
+	prefix := dir
+	if prefix != "/" {
+		prefix += "/"
+	}
+
+	for _, rule := range fs.rules {
+		if rule.re != nil || rule.from == "/" || !strings.HasPrefix(rule.from, prefix) {
+			continue
+		}
+
+		rest := rule.from[len(prefix):]
+		name := rest
+		if idx := strings.Index(rest, "/"); idx != -1 {
+			name = rest[:idx]
+		}
+
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if name == rest {
+			aliases = append(aliases, name)
+		} else {
+			dirs = append(dirs, name)
+		}
+	}
+	return aliases, dirs
+}
+
+func (d *RewriteDir) load() error {
+	if d.loaded {
+		return nil
+	}
+
+	merged := make(map[string]os.FileInfo)
+	entries, err := d.File.Readdir(-1)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		target, ok := d.fs.rewrite(path.Join(d.dirPath, name))
+		if !ok || target == path.Join(d.targetPath, name) {
+			merged[name] = entry
+		}
+	}
+
+	// The names matched by the rules, including the ones we have just skipped,
+	// show what they are mapped onto
+	stat := func(name string) error {
+		target, _ := d.fs.rewrite(path.Join(d.dirPath, name))
+		file, err := d.fs.fs.Open(target)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		merged[name] = renamedInfo{info, name}
+		return nil
+	}
+
+	for _, entry := range entries {
+		if _, ok := merged[entry.Name()]; !ok {
+			if err := stat(entry.Name()); err != nil {
+				return err
+			}
+		}
+	}
+
+	aliases, dirs := d.fs.childAliases(d.dirPath)
+	for _, name := range aliases {
+		if err := stat(name); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range dirs {
+		if info, ok := merged[name]; ok && info.IsDir() {
+			continue
+		}
+		merged[name] = dirInfo{name}
+	}
+
+	d.entries = make([]os.FileInfo, 0, len(merged))
+	for _, info := range merged {
+		d.entries = append(d.entries, info)
+	}
+	sortEntries(d.entries)
+	d.loaded = true
+	return nil
+}
+
+func (d *RewriteDir) Readdir(count int) ([]os.FileInfo, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	return readdirPage(d.entries, &d.offset, count)
+}
+
+func (fs RewriteFileSystem) Open(name string) (http.File, error) {
+	name = path.Clean("/" + name)
+	target, rewritten := fs.rewrite(name)
+
+	file, err := fs.fs.Open(target)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		// The directories leading to the aliases exist even if nothing is there
+		if aliases, dirs := fs.childAliases(name); len(aliases) == 0 && len(dirs) == 0 {
+			return nil, err
+		}
+		file = newSyntheticDir(name)
+	}
+
+	if rewritten && path.Base(name) != path.Base(target) {
+		file = renamedFile{file, path.Base(name)}
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if !stat.IsDir() {
+		return file, nil
+	}
+	return &RewriteDir{File: file, fs: fs, dirPath: name, targetPath: target}, nil
+}
+
+// Build a new rewriting filesystem. The rules are tried in order.
+func NewRewriteFileSystem(fs http.FileSystem, rules []RewriteRule) (http.FileSystem, error) {
+	rewriteFs := RewriteFileSystem{fs: fs}
+	for _, rule := range rules {
+		parsed := rewriteRule{to: rule.To}
+		if strings.HasPrefix(rule.From, "re:") {
+			re, err := regexp.Compile(rule.From[3:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid rewrite rule %q: %w", rule.From, err)
+			}
+			parsed.re = re
+		} else {
+			parsed.prefix = strings.HasSuffix(rule.From, "/")
+			parsed.from = path.Clean("/" + rule.From)
+			parsed.to = path.Clean("/" + rule.To)
+		}
+		rewriteFs.rules = append(rewriteFs.rules, parsed)
+	}
+	return rewriteFs, nil
+}
@@ -0,0 +1,543 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type S3Options struct {
+	// The URL of the service, ie. "https://s3.eu-central-1.amazonaws.com"; the
+	// buckets are addressed path-style
+	Endpoint string
+	Region   string
+	Bucket   string
+
+	// The key prefix under which the served tree lives, ie. "assets/v2"
+	Prefix string
+
+	// The credentials; the requests are sent unsigned if AccessKey is empty
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+
+	// How long the metadata and the listings are cached; a minute if zero,
+	// not at all if negative
+	CacheTTL time.Duration
+
+	Client *http.Client
+}
+
+type s3Object struct {
+	name    string
+	size    int64
+	modTime time.Time
+	isDir   bool
+	exists  bool
+}
+
+type s3CacheEntry struct {
+	timestamp time.Time
+	object    s3Object
+	listing   []os.FileInfo
+}
+
+type s3ListResult struct {
+	IsTruncated           bool
+	NextContinuationToken string
+	Contents              []struct {
+		Key          string
+		LastModified time.Time
+		Size         int64
+	}
+	CommonPrefixes []struct {
+		Prefix string
+	}
+}
+
+// FileInfo of an object or a common prefix.
+type s3Info struct {
+	object s3Object
+}
+
+// An object of the S3 filesystem. The objects are read with ranged GET
+// requests starting at the current offset, so seeking does not download the
+// skipped data.
+type S3File struct {
+	fs     S3FileSystem
+	key    string
+	object s3Object
+	body   io.ReadCloser
+	pos    int64
+}
+
+// A http.FileSystem backed by an S3-compatible object storage. The keys are
+// split on slashes to form the directories. The expired cache entries are
+// pruned at most once per TTL, so that the cache does not grow with every key
+// ever requested.
+type S3FileSystem struct {
+	opts   S3Options
+	cache  map[string]s3CacheEntry
+	pruned *time.Time
+	mutex  *sync.Mutex
+	now    func() time.Time
+}
+
+func (i s3Info) Name() string       { return i.object.name }
+func (i s3Info) Size() int64        { return i.object.size }
+func (i s3Info) ModTime() time.Time { return i.object.modTime }
+func (i s3Info) IsDir() bool        { return i.object.isDir }
+func (i s3Info) Sys() interface{}   { return nil }
+
+func (i s3Info) Mode() os.FileMode {
+	if i.object.isDir {
+		return os.ModeDir | 0555
+	}
+	return 0444
+}
+
+// Escape the string as required by the AWS signature; the slashes are kept if
+// encoding a path.
+func s3Escape(s string, isPath bool) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (isPath && c == '/') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// Encode the query sorted by the keys and the values, escaping them like
+// s3Escape does, ie. the spaces as "%20" rather than "+"; both the request and
+// its canonical form for the signature use this encoding.
+func s3Query(query url.Values) string {
+	var keys []string
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, key := range keys {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			params = append(params, s3Escape(key, false)+"="+s3Escape(value, false))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// Sign the request with AWS Signature Version 4. The host, the range and the
+// x-amz-* headers are signed; the payload is not, unless its hash has been set
+// in the x-amz-content-sha256 header.
+func signV4(req *http.Request, accessKey, secretKey, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if req.Header.Get("X-Amz-Content-Sha256") == "" {
+		req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "range" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3Escape(req.URL.Path, true),
+		s3Query(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" +
+		hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func (fs S3FileSystem) fullKey(key string) string {
+	prefix := strings.Trim(fs.opts.Prefix, "/")
+	if prefix == "" {
+		return key
+	}
+	if key == "" {
+		return prefix
+	}
+	return prefix + "/" + key
+}
+
+func (fs S3FileSystem) do(method, key string, query url.Values, header http.Header) (*http.Response, error) {
+	u := strings.TrimSuffix(fs.opts.Endpoint, "/") + "/" + s3Escape(fs.opts.Bucket, false)
+	if key != "" {
+		u += "/" + s3Escape(key, true)
+	}
+	if len(query) != 0 {
+		u += "?" + s3Query(query)
+	}
+
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	if fs.opts.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", fs.opts.SessionToken)
+	}
+
+	if fs.opts.AccessKey != "" {
+		signV4(req, fs.opts.AccessKey, fs.opts.SecretKey, fs.opts.Region, fs.now())
+	}
+	return fs.opts.Client.Do(req)
+}
+
+func s3Error(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("S3 request failed with %s: %s", resp.Status, strings.TrimSpace(string(data)))
+}
+
+func (fs S3FileSystem) cached(key string) (s3CacheEntry, bool) {
+	if fs.opts.CacheTTL < 0 {
+		return s3CacheEntry{}, false
+	}
+
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	entry, ok := fs.cache[key]
+	if !ok || fs.now().Sub(entry.timestamp) > fs.opts.CacheTTL {
+		return s3CacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (fs S3FileSystem) store(key string, update func(entry *s3CacheEntry)) {
+	if fs.opts.CacheTTL < 0 {
+		return
+	}
+
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	now := fs.now()
+	if now.Sub(*fs.pruned) > fs.opts.CacheTTL {
+		for cachedKey, entry := range fs.cache {
+			if now.Sub(entry.timestamp) > fs.opts.CacheTTL {
+				delete(fs.cache, cachedKey)
+			}
+		}
+		*fs.pruned = now
+	}
+
+	entry, ok := fs.cache[key]
+	if !ok || now.Sub(entry.timestamp) > fs.opts.CacheTTL {
+		entry = s3CacheEntry{timestamp: now}
+	}
+	update(&entry)
+	fs.cache[key] = entry
+}
+
+// List the objects and the common prefixes directly below the prefix.
+func (fs S3FileSystem) list(prefix string, maxKeys int) ([]os.FileInfo, error) {
+	var infos []os.FileInfo
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("delimiter", "/")
+		query.Set("prefix", prefix)
+		if maxKeys > 0 {
+			query.Set("max-keys", strconv.Itoa(maxKeys))
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := fs.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			err := s3Error(resp)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, prefixEntry := range result.CommonPrefixes {
+			name := path.Base(strings.TrimSuffix(prefixEntry.Prefix, "/"))
+			infos = append(infos, s3Info{s3Object{name: name, isDir: true, exists: true}})
+		}
+
+		for _, content := range result.Contents {
+			// Skip the folder markers
+			if content.Key == prefix || strings.HasSuffix(content.Key, "/") {
+				continue
+			}
+			infos = append(infos, s3Info{s3Object{
+				name:    path.Base(content.Key),
+				size:    content.Size,
+				modTime: content.LastModified,
+				exists:  true,
+			}})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" ||
+			(maxKeys > 0 && len(infos) >= maxKeys) {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+
+	sortEntries(infos)
+	return infos, nil
+}
+
+// List the directory; each caller gets its own copy of the cached listing.
+func (fs S3FileSystem) listDir(key string) ([]os.FileInfo, error) {
+	if entry, ok := fs.cached(key); ok && entry.listing != nil {
+		return append(make([]os.FileInfo, 0, len(entry.listing)), entry.listing...), nil
+	}
+
+	prefix := fs.fullKey(key)
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	entries, err := fs.list(prefix, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	listing := append(make([]os.FileInfo, 0, len(entries)), entries...)
+	fs.store(key, func(entry *s3CacheEntry) { entry.listing = listing })
+	return entries, nil
+}
+
+func (fs S3FileSystem) stat(key string) (s3Object, error) {
+	if entry, ok := fs.cached(key); ok && entry.object.name != "" {
+		return entry.object, nil
+	}
+
+	object := s3Object{name: path.Base(key)}
+	resp, err := fs.do(http.MethodHead, fs.fullKey(key), nil, nil)
+	if err != nil {
+		return object, err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		object.exists = true
+		object.size = resp.ContentLength
+		object.modTime, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	case http.StatusNotFound, http.StatusForbidden:
+		// Without the list permission S3 reports the missing objects with 403;
+		// either way, the key may still be a prefix
+		infos, err := fs.list(fs.fullKey(key)+"/", 1)
+		if err != nil {
+			return object, err
+		}
+		object.exists = len(infos) != 0
+		object.isDir = true
+	default:
+		return object, fmt.Errorf("S3 request failed with %s", resp.Status)
+	}
+
+	fs.store(key, func(entry *s3CacheEntry) { entry.object = object })
+	return object, nil
+}
+
+func (f *S3File) Read(p []byte) (int, error) {
+	if f.pos >= f.object.size {
+		return 0, io.EOF
+	}
+
+	if f.body == nil {
+		header := http.Header{}
+		header.Set("Range", fmt.Sprintf("bytes=%d-", f.pos))
+		resp, err := f.fs.do(http.MethodGet, f.fs.fullKey(f.key), nil, header)
+		if err != nil {
+			return 0, err
+		}
+
+		if resp.StatusCode != http.StatusPartialContent && resp.StatusCode != http.StatusOK {
+			err := s3Error(resp)
+			resp.Body.Close()
+			return 0, err
+		}
+
+		// A server ignoring the range sends everything from the beginning
+		if resp.StatusCode == http.StatusOK && f.pos != 0 {
+			if _, err := io.CopyN(io.Discard, resp.Body, f.pos); err != nil {
+				resp.Body.Close()
+				return 0, err
+			}
+		}
+		f.body = resp.Body
+	}
+
+	n, err := f.body.Read(p)
+	f.pos += int64(n)
+	if err == io.EOF && f.pos < f.object.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (f *S3File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.pos
+	case io.SeekEnd:
+		offset += f.object.size
+	default:
+		return 0, fmt.Errorf("invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position")
+	}
+
+	if offset != f.pos && f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	f.pos = offset
+	return offset, nil
+}
+
+func (f *S3File) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, fmt.Errorf("cannot Readdir from a file")
+}
+
+func (f *S3File) Stat() (os.FileInfo, error) {
+	return s3Info{f.object}, nil
+}
+
+func (f *S3File) Close() error {
+	if f.body == nil {
+		return nil
+	}
+	err := f.body.Close()
+	f.body = nil
+	return err
+}
+
+func (fs S3FileSystem) Open(name string) (http.File, error) {
+	key := strings.TrimPrefix(path.Clean("/"+name), "/")
+	object := s3Object{name: "/", isDir: true, exists: true}
+	if key != "" {
+		var err error
+		object, err = fs.stat(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !object.exists {
+		return nil, os.ErrNotExist
+	}
+
+	if object.isDir {
+		return &dirHandle{info: s3Info{object}, load: func() ([]os.FileInfo, error) {
+			return fs.listDir(key)
+		}}, nil
+	}
+	return &S3File{fs: fs, key: key, object: object}, nil
+}
+
+// Build a new filesystem serving the objects of an S3 bucket.
+func NewS3FileSystem(opts S3Options) http.FileSystem {
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = time.Minute
+	}
+
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	return S3FileSystem{
+		opts:   opts,
+		cache:  make(map[string]s3CacheEntry),
+		pruned: &time.Time{},
+		mutex:  &sync.Mutex{},
+		now:    time.Now,
+	}
+}
@@ -0,0 +1,215 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"encoding/xml"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type s3StubContents struct {
+	Key          string
+	LastModified string
+	Size         int64
+	StorageClass string
+}
+
+type s3StubPrefix struct {
+	Prefix string
+}
+
+type s3StubListResult struct {
+	XMLName               xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name                  string
+	Prefix                string
+	Delimiter             string `xml:",omitempty"`
+	MaxKeys               int
+	KeyCount              int
+	IsTruncated           bool
+	ContinuationToken     string `xml:",omitempty"`
+	NextContinuationToken string `xml:",omitempty"`
+	Contents              []s3StubContents
+	CommonPrefixes        []s3StubPrefix
+}
+
+type s3StubError struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string
+	Message  string
+	Resource string
+}
+
+// A local stand-in for an S3-compatible service serving a single bucket out
+// of a http.FileSystem. It understands just enough of the API for the
+// S3FileSystem, ie. ListObjectsV2 and the ranged GET and HEAD requests of the
+// objects, and does not check the signatures. Meant for the development
+// setups and the tests.
+type S3StubHandler struct {
+	bucket string
+	fs     http.FileSystem
+}
+
+func (h S3StubHandler) fail(w http.ResponseWriter, r *http.Request, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(s3StubError{Code: code, Message: msg, Resource: r.URL.Path})
+}
+
+// Collect the files as keys; the directories that cannot be listed are
+// skipped.
+func (h S3StubHandler) objects() []s3StubContents {
+	var objects []s3StubContents
+	Walk(h.fs, "/", func(name string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		objects = append(objects, s3StubContents{
+			Key:          strings.TrimPrefix(name, "/"),
+			LastModified: info.ModTime().UTC().Format("2006-01-02T15:04:05.000Z"),
+			Size:         info.Size(),
+			StorageClass: "STANDARD",
+		})
+		return nil
+	})
+	return objects
+}
+
+func (h S3StubHandler) serveList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("list-type") != "2" {
+		h.fail(w, r, http.StatusNotImplemented, "NotImplemented",
+			"Only ListObjectsV2 is supported")
+		return
+	}
+
+	result := s3StubListResult{
+		Name:              h.bucket,
+		Prefix:            query.Get("prefix"),
+		Delimiter:         query.Get("delimiter"),
+		MaxKeys:           1000,
+		ContinuationToken: query.Get("continuation-token"),
+	}
+
+	if maxKeys := query.Get("max-keys"); maxKeys != "" {
+		n, err := strconv.Atoi(maxKeys)
+		if err != nil || n < 0 {
+			h.fail(w, r, http.StatusBadRequest, "InvalidArgument", "Invalid max-keys")
+			return
+		}
+		if n < result.MaxKeys {
+			result.MaxKeys = n
+		}
+	}
+
+	// The continuation token is simply the last key or prefix returned
+	after := query.Get("start-after")
+	if result.ContinuationToken != "" {
+		after = result.ContinuationToken
+	}
+
+	objects := h.objects()
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+
+	last := ""
+	for _, object := range objects {
+		if !strings.HasPrefix(object.Key, result.Prefix) || object.Key <= after {
+			continue
+		}
+
+		entry := object.Key
+		isPrefix := false
+		if result.Delimiter != "" {
+			rest := object.Key[len(result.Prefix):]
+			if idx := strings.Index(rest, result.Delimiter); idx != -1 {
+				entry = result.Prefix + rest[:idx+len(result.Delimiter)]
+				isPrefix = true
+			}
+		}
+
+		// The keys sharing a common prefix are listed once
+		if entry == last || (isPrefix && entry <= after) {
+			continue
+		}
+
+		if result.KeyCount == result.MaxKeys {
+			result.IsTruncated = true
+			result.NextContinuationToken = last
+			break
+		}
+
+		if isPrefix {
+			result.CommonPrefixes = append(result.CommonPrefixes, s3StubPrefix{entry})
+		} else {
+			result.Contents = append(result.Contents, object)
+		}
+		last = entry
+		result.KeyCount++
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(result)
+}
+
+func (h S3StubHandler) serveObject(w http.ResponseWriter, r *http.Request, key string) {
+	file, err := h.fs.Open("/" + key)
+	if err != nil {
+		h.fail(w, r, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		h.fail(w, r, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	w.Header().Set("Accept-Ranges", "bytes")
+	http.ServeContent(w, r, key, info.ModTime().UTC().Truncate(time.Second), file)
+}
+
+func (h S3StubHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		h.fail(w, r, http.StatusNotImplemented, "NotImplemented",
+			"Only GET and HEAD requests are supported")
+		return
+	}
+
+	bucket := strings.TrimPrefix(r.URL.Path, "/")
+	key := ""
+	if idx := strings.Index(bucket, "/"); idx != -1 {
+		bucket, key = bucket[:idx], bucket[idx+1:]
+	}
+
+	if bucket != h.bucket {
+		h.fail(w, r, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist.")
+		return
+	}
+
+	if key == "" {
+		h.serveList(w, r)
+		return
+	}
+	h.serveObject(w, r, key)
+}
+
+// Build a new S3 stand-in serving the bucket out of the filesystem at the
+// path-style URLs, ie. "/bucket/key".
+func NewS3StubHandler(bucket string, fs http.FileSystem) http.Handler {
+	return S3StubHandler{bucket, fs}
+}
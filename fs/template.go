@@ -0,0 +1,255 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+type TemplateOptions struct {
+	// Provide the data for rendering the file of the given name
+	Data func(name string) (interface{}, error)
+
+	// Functions available to the templates, ie. Manifest.TemplateFuncs()
+	Funcs template.FuncMap
+
+	// How long the rendered files are cached. If zero, they are cached until
+	// their sources change, unless Data is set, in which case they are
+	// rendered whenever they are opened
+	TTL time.Duration
+}
+
+// FileInfo of a rendered file.
+type renderedInfo struct {
+	os.FileInfo
+	size    int64
+	modTime time.Time
+}
+
+func (i renderedInfo) Size() int64        { return i.size }
+func (i renderedInfo) ModTime() time.Time { return i.modTime }
+
+type renderedEntry struct {
+	srcModTime time.Time
+	srcSize    int64
+	rendered   time.Time
+	data       []byte
+	etag       string
+	info       renderedInfo
+}
+
+// A file rendered from a template.
+type RenderedFile struct {
+	*bytes.Reader
+	info renderedInfo
+	etag string
+}
+
+type TemplateDir struct {
+	http.File
+	fs      TemplateFileSystem
+	dirPath string
+}
+
+// Renders the selected files of the underlying filesystem as templates. The
+// HTML files are rendered with html/template, the rest with text/template.
+type TemplateFileSystem struct {
+	fs    http.FileSystem
+	rules []filterRule
+	opts  TemplateOptions
+	cache map[string]renderedEntry
+	mutex *sync.Mutex
+}
+
+func (f RenderedFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, fmt.Errorf("cannot Readdir from a file")
+}
+
+func (f RenderedFile) Stat() (os.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f RenderedFile) Close() error {
+	return nil
+}
+
+// The strong ETag of the rendered content.
+func (f RenderedFile) ETag() string {
+	return f.etag
+}
+
+func (fs TemplateFileSystem) isTemplate(name string) bool {
+	components := splitPath(name)
+	for _, rule := range fs.rules {
+		if rule.matches(components) {
+			return true
+		}
+	}
+	return false
+}
+
+func (fs TemplateFileSystem) execute(name string, src []byte) ([]byte, error) {
+	var data interface{}
+	if fs.opts.Data != nil {
+		var err error
+		if data, err = fs.opts.Data(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	ext := strings.ToLower(path.Ext(name))
+	if ext == ".html" || ext == ".htm" {
+		funcs := htmltemplate.FuncMap(fs.opts.Funcs)
+		tmpl, err := htmltemplate.New(name).Funcs(funcs).Parse(string(src))
+		if err != nil {
+			return nil, err
+		}
+		err = tmpl.Execute(&buf, data)
+		return buf.Bytes(), err
+	}
+
+	tmpl, err := template.New(name).Funcs(fs.opts.Funcs).Parse(string(src))
+	if err != nil {
+		return nil, err
+	}
+	err = tmpl.Execute(&buf, data)
+	return buf.Bytes(), err
+}
+
+func (fs TemplateFileSystem) render(name string, file http.File, stat os.FileInfo) (renderedEntry, error) {
+	fs.mutex.Lock()
+	cached, ok := fs.cache[name]
+	fs.mutex.Unlock()
+
+	fresh := fs.opts.Data == nil
+	if fs.opts.TTL != 0 {
+		fresh = time.Since(cached.rendered) < fs.opts.TTL
+	}
+
+	if ok && fresh && cached.srcModTime.Equal(stat.ModTime()) && cached.srcSize == stat.Size() {
+		return cached, nil
+	}
+
+	src, err := io.ReadAll(file)
+	if err != nil {
+		return renderedEntry{}, err
+	}
+
+	data, err := fs.execute(name, src)
+	if err != nil {
+		return renderedEntry{}, fmt.Errorf("cannot render %s: %s", name, err)
+	}
+
+	now := time.Now()
+	entry := renderedEntry{
+		srcModTime: stat.ModTime(),
+		srcSize:    stat.Size(),
+		rendered:   now,
+		data:       data,
+		etag:       contentETag(data),
+		info:       renderedInfo{stat, int64(len(data)), now},
+	}
+
+	// Keep the modification time stable as long as the content does not change
+	if ok && entry.etag == cached.etag {
+		entry.info.modTime = cached.info.modTime
+	}
+
+	fs.mutex.Lock()
+	fs.cache[name] = entry
+	fs.mutex.Unlock()
+	return entry, nil
+}
+
+func (fs TemplateFileSystem) renderPath(name string) (renderedEntry, error) {
+	file, err := fs.fs.Open(name)
+	if err != nil {
+		return renderedEntry{}, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return renderedEntry{}, err
+	}
+	return fs.render(name, file, stat)
+}
+
+func (d TemplateDir) Readdir(count int) ([]os.FileInfo, error) {
+	entries, err := d.File.Readdir(count)
+	for i, entry := range entries {
+		name := path.Join(d.dirPath, entry.Name())
+		if entry.IsDir() || !d.fs.isTemplate(name) {
+			continue
+		}
+
+		rendered, renderErr := d.fs.renderPath(name)
+		if renderErr != nil {
+			return nil, renderErr
+		}
+		entries[i] = rendered.info
+	}
+	return entries, err
+}
+
+func (fs TemplateFileSystem) Open(name string) (http.File, error) {
+	file, err := fs.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	name = path.Clean("/" + name)
+	if stat.IsDir() {
+		return TemplateDir{file, fs, name}, nil
+	}
+
+	if !fs.isTemplate(name) {
+		return file, nil
+	}
+
+	defer file.Close()
+	entry, err := fs.render(name, file, stat)
+	if err != nil {
+		return nil, err
+	}
+	return RenderedFile{bytes.NewReader(entry.data), entry.info, entry.etag}, nil
+}
+
+// Build a new template filesystem. The files matching the patterns, ie.
+// "/index.html" or "**/config.js", are rendered when opened.
+func NewTemplateFileSystem(fs http.FileSystem, patterns []string, opts TemplateOptions) (http.FileSystem, error) {
+	rules, err := parseRules(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	return TemplateFileSystem{
+		fs:    fs,
+		rules: rules,
+		opts:  opts,
+		cache: make(map[string]renderedEntry),
+		mutex: &sync.Mutex{},
+	}, nil
+}
@@ -0,0 +1,120 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"errors"
+	"net/http"
+	"os"
+)
+
+// A directory of the union filesystem. Its listing merges the listings of the
+// same directory in all the layers; an entry in an upper layer hides the
+// entries of the same name in the lower ones.
+type UnionDir struct {
+	http.File
+	dirs    []http.File
+	entries []os.FileInfo
+	loaded  bool
+	offset  int
+}
+
+type UnionFileSystem struct {
+	layers []http.FileSystem
+}
+
+func (d *UnionDir) load() error {
+	if d.loaded {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, dir := range d.dirs {
+		entries, err := dir.Readdir(-1)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if seen[entry.Name()] {
+				continue
+			}
+			seen[entry.Name()] = true
+			d.entries = append(d.entries, entry)
+		}
+	}
+
+	sortEntries(d.entries)
+	d.loaded = true
+	return nil
+}
+
+func (d *UnionDir) Readdir(count int) ([]os.FileInfo, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+	return readdirPage(d.entries, &d.offset, count)
+}
+
+func (d *UnionDir) Close() error {
+	var err error
+	for _, dir := range d.dirs {
+		if e := dir.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func closeAll(files []http.File) {
+	for _, file := range files {
+		file.Close()
+	}
+}
+
+func (fs UnionFileSystem) Open(name string) (http.File, error) {
+	var dirs []http.File
+	for _, layer := range fs.layers {
+		file, err := layer.Open(name)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			closeAll(dirs)
+			return nil, err
+		}
+
+		stat, err := file.Stat()
+		if err != nil {
+			file.Close()
+			closeAll(dirs)
+			return nil, err
+		}
+
+		// A file in an upper layer wins; a file in a lower layer is hidden by
+		// the directories above it
+		if !stat.IsDir() {
+			if len(dirs) == 0 {
+				return file, nil
+			}
+			file.Close()
+			break
+		}
+		dirs = append(dirs, file)
+	}
+
+	if len(dirs) == 0 {
+		return nil, os.ErrNotExist
+	}
+	return &UnionDir{File: dirs[0], dirs: dirs}, nil
+}
+
+// Build a new union filesystem. The layers are listed from the top-most one;
+// the first layer that contains a file serves it.
+func NewUnionFileSystem(layers ...http.FileSystem) http.FileSystem {
+	return UnionFileSystem{layers}
+}
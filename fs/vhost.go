@@ -0,0 +1,136 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type VirtualHost struct {
+	// The host name, ie. "example.com"; "*.example.com" matches all the
+	// subdomains of example.com, and "*" matches any host
+	Pattern string
+
+	Fs http.FileSystem
+
+	// The whitelist applied to the filesystem, as in NewFilteredFileSystem;
+	// everything is served if empty
+	Whitelist []string
+
+	// Serve the filesystem as a single page app falling back to "/index.html"
+	// or to the index files given for the path prefixes, as in Index404Fs
+	Spa       bool
+	Fallbacks map[string]string
+
+	// The handler serving the host; built from the above if nil
+	Handler http.Handler
+}
+
+// Routes the requests to the handlers of the virtual hosts according to the
+// Host header. The exact host names win over the wildcards, and the longer
+// wildcards win over the shorter ones.
+type VirtualHostHandler struct {
+	exact     map[string]http.Handler
+	wildcards map[string]http.Handler
+	fallback  http.Handler
+}
+
+// Normalize the host name: strip the port and the trailing dot, and lower the
+// case.
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimPrefix(strings.TrimSuffix(host, "]"), "[")
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// Find the handler for the host.
+func (h VirtualHostHandler) match(host string) http.Handler {
+	host = normalizeHost(host)
+	if handler, ok := h.exact[host]; ok {
+		return handler
+	}
+
+	// Strip the labels one by one, so the longest suffix is found first
+	for idx := strings.Index(host, "."); idx != -1; {
+		host = host[idx+1:]
+		if handler, ok := h.wildcards[host]; ok {
+			return handler
+		}
+		idx = strings.Index(host, ".")
+	}
+	return h.fallback
+}
+
+func (h VirtualHostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler := h.match(r.Host)
+	if handler == nil {
+		http.NotFound(w, r)
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// Build a new virtual host router. The requests for the hosts matching none of
+// the patterns get a 404.
+func NewVirtualHostHandler(hosts []VirtualHost) (http.Handler, error) {
+	router := VirtualHostHandler{
+		exact:     make(map[string]http.Handler),
+		wildcards: make(map[string]http.Handler),
+	}
+
+	for _, host := range hosts {
+		handler := host.Handler
+		if handler == nil {
+			if host.Fs == nil {
+				return nil, fmt.Errorf("virtual host %q has neither a filesystem nor a handler", host.Pattern)
+			}
+
+			fs := host.Fs
+			if len(host.Whitelist) != 0 {
+				filteredFs, err := NewWhitelistedFileSystem(fs, host.Whitelist)
+				if err != nil {
+					return nil, fmt.Errorf("virtual host %q: %w", host.Pattern, err)
+				}
+				fs = filteredFs
+			}
+
+			if host.Spa || len(host.Fallbacks) != 0 {
+				handler = &Index404Fs{Fs: fs, Fallbacks: host.Fallbacks}
+			} else {
+				handler = http.FileServer(fs)
+			}
+		}
+
+		pattern := normalizeHost(host.Pattern)
+		var seen bool
+		switch {
+		case pattern == "*":
+			seen = router.fallback != nil
+			router.fallback = handler
+		case strings.HasPrefix(pattern, "*."):
+			pattern = pattern[2:]
+			_, seen = router.wildcards[pattern]
+			router.wildcards[pattern] = handler
+		case pattern == "" || strings.Contains(pattern, "*"):
+			return nil, fmt.Errorf("invalid virtual host pattern %q", host.Pattern)
+		default:
+			_, seen = router.exact[pattern]
+			router.exact[pattern] = handler
+		}
+
+		if seen {
+			return nil, fmt.Errorf("duplicate virtual host pattern %q", host.Pattern)
+		}
+	}
+	return router, nil
+}
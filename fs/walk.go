@@ -0,0 +1,277 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// Called by Walk for every file and directory; err is the error of opening
+// or listing the file, if any, and info is nil if the file cannot be opened.
+type WalkFunc func(name string, info os.FileInfo, err error) error
+
+type DiffKind int
+
+const (
+	// The file exists only in the second filesystem
+	DiffAdded DiffKind = iota
+
+	// The file exists only in the first filesystem
+	DiffRemoved
+
+	// The file exists in both filesystems but its contents differ
+	DiffModified
+)
+
+type DiffEntry struct {
+	Name  string
+	Kind  DiffKind
+	SizeA int64
+	SizeB int64
+}
+
+type treeNode struct {
+	name     string
+	info     os.FileInfo
+	size     int64
+	files    int
+	children []*treeNode
+}
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+func (e DiffEntry) String() string {
+	switch e.Kind {
+	case DiffAdded:
+		return fmt.Sprintf("+ %s (%s)", e.Name, formatSize(e.SizeB))
+	case DiffRemoved:
+		return fmt.Sprintf("- %s (%s)", e.Name, formatSize(e.SizeA))
+	}
+	return fmt.Sprintf("M %s (%s -> %s)", e.Name, formatSize(e.SizeA), formatSize(e.SizeB))
+}
+
+// Format the size in the units of 1024 bytes, ie. "1.5K".
+func formatSize(size int64) string {
+	if size < 1024 {
+		return fmt.Sprintf("%dB", size)
+	}
+
+	value := float64(size)
+	for _, unit := range []string{"K", "M", "G", "T"} {
+		value /= 1024
+		if value < 1024 || unit == "T" {
+			return fmt.Sprintf("%.1f%s", value, unit)
+		}
+	}
+	return ""
+}
+
+func walk(fs http.FileSystem, name string, info os.FileInfo, fn WalkFunc) error {
+	if err := fn(name, info, nil); err != nil {
+		if info.IsDir() && errors.Is(err, filepath.SkipDir) {
+			return nil
+		}
+		return err
+	}
+
+	if !info.IsDir() {
+		return nil
+	}
+
+	dir, err := fs.Open(name)
+	if err != nil {
+		return fn(name, info, err)
+	}
+
+	entries, err := dir.Readdir(-1)
+	dir.Close()
+	if err != nil {
+		return fn(name, info, err)
+	}
+	sortEntries(entries)
+
+	for _, entry := range entries {
+		// A SkipDir coming from a file skips the rest of the directory
+		err := walk(fs, path.Join(name, entry.Name()), entry, fn)
+		if errors.Is(err, filepath.SkipDir) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Walk the tree rooted at the given path in lexical order, like filepath.Walk
+// does on disk. Returning filepath.SkipDir from the function skips the
+// directory, or the remaining entries of the directory of a file.
+func Walk(fs http.FileSystem, root string, fn WalkFunc) error {
+	root = path.Clean("/" + root)
+	file, err := fs.Open(root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+
+	info, err := file.Stat()
+	file.Close()
+	if err != nil {
+		return fn(root, nil, err)
+	}
+
+	err = walk(fs, root, info, fn)
+	if errors.Is(err, filepath.SkipDir) {
+		return nil
+	}
+	return err
+}
+
+func printTree(w io.Writer, node *treeNode, prefix string) {
+	for i, child := range node.children {
+		branch, indent := "├── ", "│   "
+		if i == len(node.children)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		name := child.info.Name()
+		if child.info.IsDir() {
+			name += "/"
+		}
+		fmt.Fprintf(w, "%s%s%s (%s)\n", prefix, branch, name, formatSize(child.size))
+		printTree(w, child, prefix+indent)
+	}
+}
+
+// Print the tree rooted at the given path with the sizes of the files and the
+// total sizes of the directories.
+func PrintTree(w io.Writer, fs http.FileSystem, root string) error {
+	var stack []*treeNode
+	var top *treeNode
+	var dirs int
+
+	err := Walk(fs, root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		node := &treeNode{name: name, info: info}
+		if !info.IsDir() {
+			node.size = info.Size()
+			node.files = 1
+		} else {
+			dirs++
+		}
+
+		// Pop the directories that the walk has left
+		for len(stack) != 0 && path.Dir(name) != stack[len(stack)-1].name {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) == 0 {
+			top = node
+		} else {
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+		}
+
+		if info.IsDir() {
+			stack = append(stack, node)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var sum func(node *treeNode)
+	sum = func(node *treeNode) {
+		for _, child := range node.children {
+			sum(child)
+			node.size += child.size
+			node.files += child.files
+		}
+	}
+	sum(top)
+
+	fmt.Fprintf(w, "%s (%s)\n", path.Clean("/"+root), formatSize(top.size))
+	printTree(w, top, "")
+	if top.info.IsDir() {
+		dirs--
+	}
+	fmt.Fprintf(w, "\n%d directories, %d files, %s\n", dirs, top.files, formatSize(top.size))
+	return nil
+}
+
+func fileSizes(fs http.FileSystem) (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	err := Walk(fs, "/", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		if !info.IsDir() {
+			sizes[name] = info.Size()
+		}
+		return nil
+	})
+	return sizes, err
+}
+
+// Compare the files of the two filesystems by their contents, ie. the
+// development assets with the embedded ones. The directories are compared
+// through the files they contain. The differences are sorted by name.
+func Diff(a, b http.FileSystem) ([]DiffEntry, error) {
+	var digests [2]IntegrityManifest
+	var sizes [2]map[string]int64
+	for i, fs := range []http.FileSystem{a, b} {
+		var err error
+		if digests[i], err = ComputeIntegrity(fs); err != nil {
+			return nil, err
+		}
+		if sizes[i], err = fileSizes(fs); err != nil {
+			return nil, err
+		}
+	}
+
+	var diff []DiffEntry
+	for name, digestA := range digests[0] {
+		digestB, ok := digests[1][name]
+		switch {
+		case !ok:
+			diff = append(diff, DiffEntry{name, DiffRemoved, sizes[0][name], 0})
+		case digestA != digestB:
+			diff = append(diff, DiffEntry{name, DiffModified, sizes[0][name], sizes[1][name]})
+		}
+	}
+
+	for name := range digests[1] {
+		if _, ok := digests[0][name]; !ok {
+			diff = append(diff, DiffEntry{name, DiffAdded, 0, sizes[1][name]})
+		}
+	}
+
+	sort.Slice(diff, func(i, j int) bool { return diff[i].Name < diff[j].Name })
+	return diff, nil
+}
@@ -0,0 +1,189 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package fs
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type WatchOptions struct {
+	// The directories on disk backing the filesystem; they are watched with
+	// inotify where available. The changed files are reported relative to the
+	// directory containing them, so the directories should be the roots of
+	// the filesystem
+	Dirs []string
+
+	// How often the filesystem is scanned for changes if inotify cannot be
+	// used; a second if zero
+	PollInterval time.Duration
+
+	// Scan the filesystem even if inotify is available
+	ForcePolling bool
+}
+
+type ChangeEvent struct {
+	// The path of the changed file within the filesystem, ie. "/js/app.js"
+	Path string
+	Time time.Time
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+	isDir   bool
+}
+
+// A filesystem that watches for the changes of its files and publishes them
+// to the subscribers.
+type WatchedFileSystem struct {
+	http.FileSystem
+	opts        WatchOptions
+	subscribers map[chan ChangeEvent]bool
+	mutex       *sync.Mutex
+	done        chan struct{}
+	closeOnce   *sync.Once
+}
+
+// Get a channel receiving the change events and a function cancelling the
+// subscription. The events are dropped if the subscriber does not keep up.
+func (fs *WatchedFileSystem) Subscribe() (<-chan ChangeEvent, func()) {
+	ch := make(chan ChangeEvent, 64)
+	fs.mutex.Lock()
+	fs.subscribers[ch] = true
+	fs.mutex.Unlock()
+
+	cancel := func() {
+		fs.mutex.Lock()
+		defer fs.mutex.Unlock()
+		if fs.subscribers[ch] {
+			delete(fs.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel
+}
+
+func (fs *WatchedFileSystem) publish(name string) {
+	event := ChangeEvent{name, time.Now()}
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	for ch := range fs.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Publish the change of a file on disk under its path within the filesystem.
+func (fs *WatchedFileSystem) publishDisk(name string) {
+	for _, dir := range fs.opts.Dirs {
+		rel, err := filepath.Rel(dir, name)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		fs.publish(path.Clean("/" + filepath.ToSlash(rel)))
+		return
+	}
+}
+
+// Stop watching and close all the subscriptions.
+func (fs *WatchedFileSystem) Close() error {
+	fs.closeOnce.Do(func() {
+		close(fs.done)
+		fs.mutex.Lock()
+		defer fs.mutex.Unlock()
+		for ch := range fs.subscribers {
+			close(ch)
+		}
+		fs.subscribers = make(map[chan ChangeEvent]bool)
+	})
+	return nil
+}
+
+// Record the states of the files; the ones that cannot be opened or listed
+// are simply skipped.
+func (fs *WatchedFileSystem) scan(states map[string]fileState) {
+	Walk(fs.FileSystem, "/", func(name string, info os.FileInfo, err error) error {
+		if err == nil && name != "/" {
+			states[name] = fileState{info.ModTime(), info.Size(), info.IsDir()}
+		}
+		return nil
+	})
+}
+
+func (fs *WatchedFileSystem) poll() {
+	ticker := time.NewTicker(fs.opts.PollInterval)
+	defer ticker.Stop()
+
+	states := make(map[string]fileState)
+	fs.scan(states)
+
+	for {
+		select {
+		case <-fs.done:
+			return
+		case <-ticker.C:
+		}
+
+		newStates := make(map[string]fileState)
+		fs.scan(newStates)
+
+		for name, state := range newStates {
+			old, ok := states[name]
+			if !ok || !old.modTime.Equal(state.modTime) || old.size != state.size ||
+				old.isDir != state.isDir {
+				fs.publish(name)
+			}
+		}
+
+		for name := range states {
+			if _, ok := newStates[name]; !ok {
+				fs.publish(name)
+			}
+		}
+		states = newStates
+	}
+}
+
+// Wrap the filesystem and start watching it. The directories on disk are
+// watched with inotify where available; otherwise, the filesystem itself is
+// scanned periodically.
+func NewWatchedFileSystem(fs http.FileSystem, opts WatchOptions) *WatchedFileSystem {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+
+	watched := &WatchedFileSystem{
+		FileSystem:  fs,
+		opts:        opts,
+		subscribers: make(map[chan ChangeEvent]bool),
+		mutex:       &sync.Mutex{},
+		done:        make(chan struct{}),
+		closeOnce:   &sync.Once{},
+	}
+
+	if !opts.ForcePolling && len(opts.Dirs) != 0 {
+		err := watchInotify(opts.Dirs, watched.publishDisk, watched.done)
+		if err == nil {
+			return watched
+		}
+		log.Warnf("Cannot watch with inotify, falling back to polling: %s", err)
+	}
+
+	go watched.poll()
+	return watched
+}
@@ -0,0 +1,121 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+//go:build linux
+// +build linux
+
+package fs
+
+import (
+	"bytes"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY |
+	syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_ATTRIB | syscall.IN_DELETE_SELF
+
+type inotifyWatcher struct {
+	fd    int
+	file  *os.File
+	dirs  map[int32]string
+	mutex sync.Mutex
+}
+
+// Watch the directory and all its subdirectories.
+func (w *inotifyWatcher) addRecursive(root string) error {
+	return filepath.WalkDir(root, func(name string, entry iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		wd, err := syscall.InotifyAddWatch(w.fd, name, inotifyMask)
+		if err != nil {
+			return err
+		}
+
+		w.mutex.Lock()
+		w.dirs[int32(wd)] = name
+		w.mutex.Unlock()
+		return nil
+	})
+}
+
+func (w *inotifyWatcher) readEvents(publish func(string)) {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			nameEnd := nameStart + int(event.Len)
+			offset = nameEnd
+
+			name := string(bytes.TrimRight(buf[nameStart:nameEnd], "\x00"))
+
+			w.mutex.Lock()
+			dir := w.dirs[event.Wd]
+			if event.Mask&syscall.IN_IGNORED != 0 {
+				delete(w.dirs, event.Wd)
+			}
+			w.mutex.Unlock()
+
+			if event.Mask&syscall.IN_IGNORED != 0 || dir == "" {
+				continue
+			}
+
+			changed := filepath.Join(dir, name)
+			if event.Mask&syscall.IN_ISDIR != 0 &&
+				event.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+				w.addRecursive(changed)
+			}
+			publish(changed)
+		}
+	}
+}
+
+func watchInotify(dirs []string, publish func(string), done <-chan struct{}) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return err
+	}
+
+	// A non-blocking descriptor goes to the runtime poller, so closing the file
+	// interrupts the pending reads
+	w := &inotifyWatcher{
+		fd:   fd,
+		file: os.NewFile(uintptr(fd), "inotify"),
+		dirs: make(map[int32]string),
+	}
+
+	for _, dir := range dirs {
+		if err := w.addRecursive(dir); err != nil {
+			w.file.Close()
+			return err
+		}
+	}
+
+	go w.readEvents(publish)
+	go func() {
+		<-done
+		w.file.Close()
+	}()
+	return nil
+}
@@ -0,0 +1,19 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+//go:build !linux
+// +build !linux
+
+package fs
+
+import (
+	"errors"
+)
+
+func watchInotify(dirs []string, publish func(string), done <-chan struct{}) error {
+	return errors.New("inotify is not available on this platform")
+}
@@ -0,0 +1,170 @@
+//------------------------------------------------------------------------------
+// Author: Lukasz Janyst <lukasz@jany.st>
+// Date: 19.10.2026
+//
+// Licensed under the MIT License, see the LICENSE file for details.
+//------------------------------------------------------------------------------
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ljanyst/go-srvutils/fs"
+	log "github.com/sirupsen/logrus"
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+)
+
+const usage = `Usage:
+  fsinspect [-root path] [-whitelist pattern]... [-blacklist pattern]... tree <source>
+  fsinspect [-whitelist pattern]... [-blacklist pattern]... diff <source> <source>
+
+The sources are directories or .zip, .tar, .tar.gz and .tgz archives. The diff
+exits with status 1 if the sources differ.
+
+The whitelist and the blacklist filter the source of the tree and the second
+source of the diff, so that diffing a source with itself shows what the
+filters drop.
+
+Options:
+`
+
+type patternList []string
+
+func (l *patternList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *patternList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// The closer of the sources that hold no resources.
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// Open the source as a filesystem.
+func openSource(name string) (http.FileSystem, io.Closer, error) {
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		archive, err := fs.OpenZipFileSystem(name)
+		return archive, archive, err
+	case strings.HasSuffix(name, ".tar") || strings.HasSuffix(name, ".tar.gz") ||
+		strings.HasSuffix(name, ".tgz"):
+		archive, err := fs.OpenTarFileSystem(name)
+		return archive, archive, err
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !info.IsDir() {
+		return nil, nil, fmt.Errorf("%s is neither a directory nor a supported archive", name)
+	}
+	return http.Dir(name), nopCloser{}, nil
+}
+
+// Apply the whitelist and the blacklist to the filesystem.
+func filter(source http.FileSystem, whitelist, blacklist []string) (http.FileSystem, error) {
+	filtered := source
+	if len(whitelist) != 0 {
+		var err error
+		filtered, err = fs.NewWhitelistedFileSystem(filtered, whitelist)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(blacklist) != 0 {
+		return fs.NewBlacklistedFileSystem(filtered, blacklist)
+	}
+	return filtered, nil
+}
+
+func run() int {
+	// Commandline
+	var whitelist, blacklist patternList
+	root := flag.String("root", "/", "the subtree to print")
+	flag.Var(&whitelist, "whitelist", "a whitelist entry, ie. \"glob:**/*.js\"; may be repeated")
+	flag.Var(&blacklist, "blacklist", "a blacklist entry, ie. \"**/*.map\"; may be repeated")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// Logging
+	log.SetFormatter(&prefixed.TextFormatter{
+		TimestampFormat: "2006-01-02 15:04:05",
+		FullTimestamp:   true,
+		ForceFormatting: true,
+	})
+
+	args := flag.Args()
+	if len(args) == 0 || (args[0] == "tree" && len(args) != 2) ||
+		(args[0] == "diff" && len(args) != 3) || (args[0] != "tree" && args[0] != "diff") {
+		flag.Usage()
+		return 2
+	}
+
+	// Open the sources; the archives are closed before exiting, so that
+	// the temporary files of the compressed ones are removed
+	var sources []http.FileSystem
+	for _, name := range args[1:] {
+		source, closer, err := openSource(name)
+		if err != nil {
+			log.Errorf("Cannot open %s: %s", name, err)
+			return 1
+		}
+		defer closer.Close()
+		sources = append(sources, source)
+	}
+
+	last := len(sources) - 1
+	filtered, err := filter(sources[last], whitelist, blacklist)
+	if err != nil {
+		log.Errorf("Cannot filter %s: %s", args[last+1], err)
+		return 2
+	}
+	sources[last] = filtered
+
+	// Print the tree
+	if args[0] == "tree" {
+		if err := fs.PrintTree(os.Stdout, sources[0], *root); err != nil {
+			log.Errorf("Cannot walk %s: %s", args[1], err)
+			return 1
+		}
+		return 0
+	}
+
+	// Diff the sources
+	diff, err := fs.Diff(sources[0], sources[1])
+	if err != nil {
+		log.Errorf("Cannot compare %s and %s: %s", args[1], args[2], err)
+		return 1
+	}
+
+	for _, entry := range diff {
+		fmt.Println(entry)
+	}
+
+	if len(diff) != 0 {
+		return 1
+	}
+	return 0
+}
+
+func main() {
+	os.Exit(run())
+}
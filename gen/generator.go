@@ -15,6 +15,7 @@ import (
 	"os/exec"
 	"path/filepath"
 
+	"github.com/ljanyst/go-srvutils/fs"
 	"github.com/shurcooL/vfsgen"
 	log "github.com/sirupsen/logrus"
 	prefixed "github.com/x-cray/logrus-prefixed-formatter"
@@ -41,6 +42,14 @@ type Options struct {
 	BuildTags       string
 	VariableName    string
 	Filename        string
+
+	// Fail instead of warning if the whitelist of the assets has entries
+	// matching nothing
+	StrictWhitelist bool
+
+	// Embed the SHA-256 digests of the assets under this name, ie.
+	// "/integrity.json", unless it is empty
+	IntegrityManifest string
 }
 
 func GenerateNodeProject(opts Options) error {
@@ -113,9 +122,38 @@ func GenerateNodeProject(opts Options) error {
 		}
 	}
 
+	// Check the whitelist for stale entries
+	if checker, ok := opts.Assets.(fs.WhitelistChecker); ok {
+		log.Info("Checking the whitelist...")
+		unmatched, err := checker.UnmatchedEntries()
+		if err != nil {
+			return fmt.Errorf("Cannot check the whitelist: %s", err)
+		}
+
+		for _, entry := range unmatched {
+			log.Warnf("Whitelist entry matches nothing: %s", entry)
+		}
+
+		if opts.StrictWhitelist && len(unmatched) != 0 {
+			return fmt.Errorf("The whitelist has %d stale entries", len(unmatched))
+		}
+	} else {
+		log.Info("The assets do not report their whitelist, not checking it")
+	}
+
+	// Record the digests of the assets
+	assets := opts.Assets
+	if opts.IntegrityManifest != "" {
+		log.Infof("Recording the integrity manifest in: %s", opts.IntegrityManifest)
+		assets, err = fs.NewIntegrityFileSystem(assets, opts.IntegrityManifest)
+		if err != nil {
+			return fmt.Errorf("Cannot compute the integrity manifest: %s", err)
+		}
+	}
+
 	// Generate the asset file
 	log.Info("Generating the asset file...")
-	err = vfsgen.Generate(opts.Assets, vfsgen.Options{
+	err = vfsgen.Generate(assets, vfsgen.Options{
 		PackageName:  opts.PackageName,
 		BuildTags:    opts.BuildTags,
 		VariableName: opts.VariableName,
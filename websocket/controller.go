@@ -77,7 +77,7 @@ func (c *controller) sendMessages(clientId uint64, reqId string, msgs []Response
 				}
 				channel <- msg
 			} else {
-				log.Errorf("Trying to send a message to a non-existing client:", clientId)
+				log.Errorf("Trying to send a message to a non-existing client: %d", clientId)
 			}
 		}
 	}